@@ -0,0 +1,52 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// restore puts back the flag variables in vars when the test ends, since
+// parsing flags sets package globals.
+func restore[T any](t *testing.T, vars ...*T) {
+	t.Helper()
+	saved := make([]T, len(vars))
+	for i, v := range vars {
+		saved[i] = *v
+	}
+	t.Cleanup(func() {
+		for i, v := range vars {
+			*v = saved[i]
+		}
+	})
+}
+
+func TestBuildListFilter_App(t *testing.T) {
+	restore(t, &listApps)
+	if err := listCmd.ParseFlags([]string{"--app", "java,uvicorn"}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := buildListFilter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(f.Apps, []string{"java", "uvicorn"}) {
+		t.Errorf("list --app java,uvicorn: filter apps %v", f.Apps)
+	}
+}
+
+func TestBuildWatchFilter_App(t *testing.T) {
+	restore(t, &watchApps, &watchStates)
+	if err := watchCmd.ParseFlags([]string{"--app", "java:com.acme", "--app", "node", "--state", "listen"}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := buildWatchFilter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(f.Apps, []string{"java:com.acme", "node"}) {
+		t.Errorf("watch --app: filter apps %v", f.Apps)
+	}
+	if len(f.States) != 1 {
+		t.Errorf("watch --state listen: filter states %v", f.States)
+	}
+}
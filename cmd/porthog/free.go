@@ -9,14 +9,17 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/z1j1e/porthog/internal/adapters/platform"
 	"github.com/z1j1e/porthog/internal/core/domain"
 	"github.com/z1j1e/porthog/internal/core/services"
 )
 
 var (
-	freeCount    int
-	freeRange    string
-	freeJSON     bool
+	freeCount int
+	freeRange string
+	freeJSON  bool
+	freeSCTP  bool
+	freeIface string
 )
 
 var freeCmd = &cobra.Command{
@@ -32,8 +35,13 @@ var freeCmd = &cobra.Command{
 			portRange = &r
 		}
 
+		proto := domain.TCP
+		if freeSCTP {
+			proto = domain.SCTP
+		}
 		svc := services.NewFindFreePortService()
-		ports, err := svc.FindFree(cmd.Context(), domain.TCP, portRange, freeCount)
+		svc.SetSCTPProber(platform.NewSCTPProber())
+		ports, err := svc.FindFreeOnInterface(cmd.Context(), proto, freeIface, portRange, freeCount)
 		if err != nil {
 			return err
 		}
@@ -53,6 +61,8 @@ func init() {
 	freeCmd.Flags().IntVarP(&freeCount, "count", "c", 1, "Number of free ports to find")
 	freeCmd.Flags().StringVarP(&freeRange, "range", "r", "", "Port range (e.g., 8000-9000)")
 	freeCmd.Flags().BoolVarP(&freeJSON, "json", "j", false, "Output in JSON format")
+	freeCmd.Flags().BoolVar(&freeSCTP, "sctp", false, "Find free SCTP ports instead of TCP (Linux)")
+	freeCmd.Flags().StringVar(&freeIface, "iface", "", "Find ports free on every address of this network interface")
 }
 
 func parseRange(s string) (domain.PortRange, error) {
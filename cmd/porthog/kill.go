@@ -3,12 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/z1j1e/porthog/internal/adapters/platform"
-	"github.com/z1j1e/porthog/internal/adapters/process"
 	"github.com/z1j1e/porthog/internal/core/domain"
 	"github.com/z1j1e/porthog/internal/core/ports"
 	"github.com/z1j1e/porthog/internal/core/services"
@@ -18,20 +19,42 @@ var (
 	killForce       bool
 	killDryRun      bool
 	killForceSystem bool
+	killIPv4        bool
+	killIPv6        bool
+	killAllOwners   bool
+	killNetns       string
+	killSCTP        bool
+	killTree        bool
+	killParent      bool
+	killProject     string
 )
 
 var killCmd = &cobra.Command{
-	Use:   "kill <port>",
-	Short: "Kill the process occupying a port",
-	Args:  cobra.ExactArgs(1),
+	Use:   "kill <port|socket-path>",
+	Short: "Kill the process occupying a port or Unix socket",
+	Long: `Kill the process occupying a port or Unix socket.
+
+With --project, only processes running in that project's checkout are
+considered, and the port may be left out to stop every listener started
+from it.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if killProject != "" {
+			return cobra.MaximumNArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		port, err := strconv.ParseUint(args[0], 10, 16)
+		var arg string
+		if len(args) > 0 {
+			arg = args[0]
+		}
+		scope, err := buildKillScope(arg)
 		if err != nil {
-			return fmt.Errorf("invalid port: %s", args[0])
+			return err
 		}
 
-		enum := platform.NewEnumerator()
-		resolver := process.NewResolver()
+		enum := platform.NewEnumeratorWithOptions(enumeratorOptions(ports.EnumeratorOptions{Netns: killNetns}))
+		resolver := newResolver(false)
 		term := platform.NewTerminator()
 		svc := services.NewKillByPortService(enum, resolver, term)
 
@@ -39,25 +62,23 @@ var killCmd = &cobra.Command{
 			Force:       killForce,
 			ForceSystem: killForceSystem,
 			DryRun:      killDryRun,
+			AllOwners:   killAllOwners,
+			Tree:        killTree,
+			Parent:      killParent,
 		}
 
-		result, err := svc.Kill(cmd.Context(), uint16(port), domain.TCP, policy)
-		if err != nil {
-			return err
-		}
-
-		if result.DryRun {
-			name := "unknown"
-			if result.Process != nil && result.Process.Name != "" {
-				name = result.Process.Name
+		if arg == "" {
+			results, err := svc.KillEach(cmd.Context(), scope, policy)
+			for _, r := range results {
+				reportKill(r)
 			}
-			fmt.Fprintf(os.Stdout, "[dry-run] Would kill PID %d (%s) on port %d\n", result.PID, name, result.Port)
-			return nil
+			return err
 		}
-
-		if result.Killed {
-			fmt.Fprintf(os.Stdout, "Killed PID %d on port %d\n", result.PID, result.Port)
+		result, err := svc.KillMatching(cmd.Context(), scope, policy)
+		if err != nil {
+			return err
 		}
+		reportKill(result)
 		return nil
 	},
 }
@@ -66,4 +87,96 @@ func init() {
 	killCmd.Flags().BoolVarP(&killForce, "force", "f", false, "Force kill (SIGKILL/TerminateProcess)")
 	killCmd.Flags().BoolVar(&killDryRun, "dry-run", false, "Show what would be killed without acting")
 	killCmd.Flags().BoolVar(&killForceSystem, "force-system", false, "Allow killing critical system processes")
+	killCmd.Flags().BoolVarP(&killIPv4, "ipv4", "4", false, "Only match IPv4 listeners")
+	killCmd.Flags().BoolVarP(&killIPv6, "ipv6", "6", false, "Only match IPv6 listeners")
+	killCmd.Flags().BoolVar(&killAllOwners, "all-owners", false, "Kill every process sharing the socket, not just their common parent")
+	killCmd.Flags().BoolVar(&killSCTP, "sctp", false, "Match an SCTP endpoint instead of a TCP listener")
+	killCmd.Flags().StringVar(&killNetns, "netns", "", "Look for the socket in another network namespace (PID inside it or ip-netns name)")
+	killCmd.Flags().BoolVar(&killTree, "tree", false, "Also kill every process below the target")
+	killCmd.Flags().BoolVar(&killParent, "parent", false, "Kill the nearest non-shell ancestor of the socket's holder instead, e.g. the npm or nodemon that respawns it")
+	killCmd.Flags().StringVar(&killProject, "project", "", "Only kill processes running in this directory's project checkout (e.g. .)")
+	killCmd.MarkFlagsMutuallyExclusive("parent", "all-owners")
+}
+
+// reportKill prints the outcome of one successful kill or dry run.
+func reportKill(result *ports.TerminateResult) {
+	if result.DryRun {
+		name := "unknown"
+		if result.Process != nil && result.Process.Name != "" {
+			name = result.Process.Name
+		}
+		if h := result.Holder; h != nil {
+			name += fmt.Sprintf(", parent of %s (PID %d)", h.Name, h.PID)
+		}
+		fmt.Fprintf(os.Stdout, "[dry-run] Would kill %s (%s) on %s\n", pidLabel(result), name, targetLabel(result))
+		return
+	}
+	if result.Killed {
+		fmt.Fprintf(os.Stdout, "Killed %s on %s\n", pidLabel(result), targetLabel(result))
+	}
+}
+
+// buildKillScope interprets the kill argument as a TCP (or, with --sctp,
+// SCTP) port or, when it looks like a path ("/run/foo.sock", "./api.sock",
+// "@abstract"), a Unix socket. An empty argument, allowed with --project,
+// matches every listener of that protocol.
+func buildKillScope(arg string) (*domain.Filter, error) {
+	scope, err := killSocketScope(arg)
+	if err != nil {
+		return nil, err
+	}
+	if killProject != "" {
+		dir, err := projectDir(killProject)
+		if err != nil {
+			return nil, err
+		}
+		scope.Projects = []string{dir}
+	}
+	return scope, nil
+}
+
+func killSocketScope(arg string) (*domain.Filter, error) {
+	proto := domain.TCP
+	if killSCTP {
+		proto = domain.SCTP
+	}
+	if arg == "" {
+		return &domain.Filter{Protocols: []domain.Protocol{proto}, Families: familyFilter(killIPv4, killIPv6)}, nil
+	}
+	if port, err := strconv.ParseUint(arg, 10, 16); err == nil {
+		return &domain.Filter{
+			Ports:     []uint16{uint16(port)},
+			Protocols: []domain.Protocol{proto},
+			Families:  familyFilter(killIPv4, killIPv6),
+		}, nil
+	}
+	if !strings.ContainsRune(arg, '/') && !strings.HasPrefix(arg, "@") {
+		return nil, fmt.Errorf("invalid port or socket path: %s", arg)
+	}
+	paths := []string{arg}
+	if !strings.HasPrefix(arg, "@") {
+		// The kernel reports the path as it was bound, which may be relative.
+		if abs, err := filepath.Abs(arg); err == nil && abs != arg {
+			paths = append(paths, abs)
+		}
+	}
+	return &domain.Filter{Protocols: []domain.Protocol{domain.Unix}, Paths: paths}, nil
+}
+
+func pidLabel(r *ports.TerminateResult) string {
+	if len(r.PIDs) <= 1 {
+		return fmt.Sprintf("PID %d", r.PID)
+	}
+	pids := make([]string, len(r.PIDs))
+	for i, p := range r.PIDs {
+		pids[i] = strconv.Itoa(int(p))
+	}
+	return "PIDs " + strings.Join(pids, ", ")
+}
+
+func targetLabel(r *ports.TerminateResult) string {
+	if r.Protocol == domain.Unix {
+		return r.Path
+	}
+	return fmt.Sprintf("port %d", r.Port)
 }
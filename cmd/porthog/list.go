@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 
 	"github.com/spf13/cobra"
@@ -11,14 +13,32 @@ import (
 	"github.com/z1j1e/porthog/internal/adapters/platform"
 	"github.com/z1j1e/porthog/internal/adapters/process"
 	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 	"github.com/z1j1e/porthog/internal/core/services"
 )
 
 var (
-	listJSON bool
-	listTCP  bool
-	listUDP  bool
-	listSort string
+	listJSON       bool
+	listTCP        bool
+	listUDP        bool
+	listUnix       bool
+	listSCTP       bool
+	listRaw        bool
+	listICMP       bool
+	listIPv4       bool
+	listIPv6       bool
+	listStates     []string
+	listMetrics    bool
+	listSort       string
+	listAllNS      bool
+	listNetns      string
+	listContainers []string
+	listProjects   []string
+	listApps       []string
+	listIfaces     []string
+	listWide       bool
+	listResources  bool
+	listOutput     string
 )
 
 var listCmd = &cobra.Command{
@@ -26,23 +46,39 @@ var listCmd = &cobra.Command{
 	Short: "List listening ports with process info",
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filter := buildListFilter(args)
+		filter, err := buildListFilter(args)
+		if err != nil {
+			return err
+		}
 		sortBy := parseSortField(listSort)
+		format, wide, err := listFormat()
+		if err != nil {
+			return err
+		}
 
-		enum := platform.NewEnumerator()
-		resolver := process.NewResolver()
-		svc := services.NewListPortsService(enum, resolver)
+		enum := platform.NewEnumeratorWithOptions(enumeratorOptions(ports.EnumeratorOptions{
+			Metrics:       listMetrics,
+			AllNamespaces: listAllNS,
+			Netns:         listNetns,
+		}))
+		opts := resolverOptions(wide || format == output.FormatJSON)
+		if listResources || sortBy.NeedsUsage() {
+			opts.Usage, opts.CPUWindow = true, process.DefaultCPUWindow
+		}
+		svc := services.NewListPortsService(enum, newResolverFrom(opts))
 
 		result, err := svc.List(cmd.Context(), filter, sortBy)
 		if err != nil {
 			return err
 		}
 
-		format := output.FormatAuto
-		if listJSON {
-			format = output.FormatJSON
-		}
 		renderer := output.NewRenderer(os.Stdout, format)
+		renderer.SetWide(wide)
+		if cols := listColumns(); cols != nil {
+			if err := renderer.SetColumns(cols); err != nil {
+				return err
+			}
+		}
 		return renderer.Render(result, "list")
 	},
 }
@@ -51,16 +87,86 @@ func init() {
 	listCmd.Flags().BoolVarP(&listJSON, "json", "j", false, "Output in JSON format")
 	listCmd.Flags().BoolVar(&listTCP, "tcp", false, "Show only TCP ports")
 	listCmd.Flags().BoolVar(&listUDP, "udp", false, "Show only UDP ports")
-	listCmd.Flags().StringVar(&listSort, "sort", "port", "Sort by: port, pid, name, protocol")
+	listCmd.Flags().BoolVar(&listUnix, "unix", false, "Show Unix domain sockets")
+	listCmd.Flags().BoolVar(&listSCTP, "sctp", false, "Show SCTP endpoints and associations (Linux)")
+	listCmd.Flags().BoolVar(&listRaw, "raw", false, "Show raw IP sockets with their IP protocol (Linux)")
+	listCmd.Flags().BoolVar(&listICMP, "icmp", false, "Show ICMP (ping) sockets (Linux)")
+	listCmd.Flags().BoolVarP(&listIPv4, "ipv4", "4", false, "Show only IPv4 sockets")
+	listCmd.Flags().BoolVarP(&listIPv6, "ipv6", "6", false, "Show only IPv6 sockets")
+	listCmd.Flags().StringSliceVar(&listStates, "state", nil, "Show only sockets in these states (e.g. listen,established,time-wait)")
+	listCmd.Flags().BoolVar(&listMetrics, "metrics", false, "Show queue depths and TCP statistics (RTT, cwnd, retransmits, bytes)")
+	listCmd.Flags().BoolVar(&listAllNS, "all-namespaces", false, "List sockets in every network namespace (containers, ip netns)")
+	listCmd.Flags().StringVar(&listNetns, "netns", "", "List sockets in one network namespace, given as a PID inside it or an ip-netns name")
+	listCmd.Flags().StringSliceVar(&listIfaces, "iface", nil, "Show only sockets reachable through these network interfaces (e.g. eth0)")
+	listCmd.Flags().StringSliceVar(&listContainers, "container", nil, "Show only sockets owned by these containers (ID prefix or runtime, e.g. docker)")
+	listCmd.Flags().StringSliceVar(&listProjects, "project", nil, "Show only sockets of processes running in these directories' project checkouts (e.g. .)")
+	listCmd.Flags().StringSliceVar(&listApps, "app", nil, "Show only sockets of these apps (runtime or part of the APP label, e.g. java, uvicorn)")
+	listCmd.Flags().StringVar(&listSort, "sort", "port", "Sort by: port, pid, name, protocol, or heaviest first by cpu, rss, fds, uptime")
+	listCmd.Flags().BoolVar(&listResources, "resources", false, "Show each process's CPU (sampled over half a second), resident memory, open files and uptime")
+	listCmd.Flags().BoolVar(&listWide, "wide", false, "Show each process's full command line, with secrets masked")
+	listCmd.Flags().StringVarP(&listOutput, "output", "o", "", "Output format: table, wide, json or plain")
+}
+
+// listFormat resolves --json, --wide and --output into an output format and
+// whether command lines are shown.
+func listFormat() (output.Format, bool, error) {
+	format := output.FormatAuto
+	if listJSON {
+		format = output.FormatJSON
+	}
+	wide := listWide
+	switch listOutput {
+	case "":
+	case "table":
+		format = output.FormatTable
+	case "wide":
+		format, wide = output.FormatTable, true
+	case "json":
+		format = output.FormatJSON
+	case "plain":
+		format = output.FormatPlain
+	default:
+		return 0, false, fmt.Errorf("invalid output format %q (must be table, wide, json or plain)", listOutput)
+	}
+	return format, wide, nil
 }
 
-func buildListFilter(args []string) *domain.Filter {
+func buildListFilter(args []string) (*domain.Filter, error) {
 	f := &domain.Filter{}
-	if listTCP && !listUDP {
-		f.Protocols = []domain.Protocol{domain.TCP}
-	} else if listUDP && !listTCP {
-		f.Protocols = []domain.Protocol{domain.UDP}
+	if listTCP {
+		f.Protocols = append(f.Protocols, domain.TCP)
 	}
+	if listUDP {
+		f.Protocols = append(f.Protocols, domain.UDP)
+	}
+	if listUnix {
+		f.Protocols = append(f.Protocols, domain.Unix)
+	}
+	if listSCTP {
+		f.Protocols = append(f.Protocols, domain.SCTP)
+	}
+	if listRaw {
+		f.Protocols = append(f.Protocols, domain.Raw)
+	}
+	if listICMP {
+		f.Protocols = append(f.Protocols, domain.ICMP)
+	}
+	f.Families = familyFilter(listIPv4, listIPv6)
+	states, err := parseStates(listStates)
+	if err != nil {
+		return nil, err
+	}
+	f.States = states
+	f.Containers = listContainers
+	f.Apps = listApps
+	for _, p := range listProjects {
+		dir, err := projectDir(p)
+		if err != nil {
+			return nil, err
+		}
+		f.Projects = append(f.Projects, dir)
+	}
+	f.Interfaces = listIfaces
 	if len(args) > 0 {
 		if port, err := strconv.ParseUint(args[0], 10, 16); err == nil {
 			f.Ports = []uint16{uint16(port)}
@@ -68,7 +174,102 @@ func buildListFilter(args []string) *domain.Filter {
 			fmt.Fprintf(os.Stderr, "Warning: invalid port %q, ignoring\n", args[0])
 		}
 	}
-	return f
+	return f, nil
+}
+
+// listColumns picks the table layout for the list flags, or nil for the
+// default layout.
+func listColumns() []string {
+	var cols []string
+	if listMetrics {
+		cols = output.MetricsColumns
+	}
+	if listAllNS || listNetns != "" {
+		if cols == nil {
+			cols = output.DefaultColumns
+		}
+		cols = append([]string{"netns"}, cols...)
+	}
+	if len(listIfaces) > 0 {
+		if cols == nil {
+			cols = output.DefaultColumns
+		}
+		cols = withColumnAfter(cols, "iface", "local_addr")
+	}
+	if listResources {
+		if cols == nil {
+			cols = output.DefaultColumns
+		}
+		cols = append(slices.Clone(cols), output.UsageColumns...)
+	}
+	return cols
+}
+
+// withColumnAfter returns a copy of cols with key inserted after the column
+// after, or appended when after is not shown.
+func withColumnAfter(cols []string, key, after string) []string {
+	out := make([]string, 0, len(cols)+1)
+	for _, c := range cols {
+		out = append(out, c)
+		if c == after {
+			out = append(out, key)
+		}
+	}
+	if len(out) == len(cols) {
+		out = append(out, key)
+	}
+	return out
+}
+
+// projectDir resolves a --project argument to the root of the project
+// containing it (see process.ProjectMarkers), or to the directory itself
+// when it is in no project.
+func projectDir(arg string) (string, error) {
+	dir, err := filepath.Abs(arg)
+	if err != nil {
+		return "", err
+	}
+	// Process working directories are reported with symlinks resolved.
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		return "", fmt.Errorf("--project: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("--project: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("--project %s: not a directory", arg)
+	}
+	if root := process.FindProjectRoot(dir); root != "" {
+		return root, nil
+	}
+	return dir, nil
+}
+
+// parseStates converts --state values into socket states.
+func parseStates(names []string) ([]domain.SocketState, error) {
+	var states []domain.SocketState
+	for _, n := range names {
+		st, err := domain.ParseSocketState(n)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, st)
+	}
+	return states, nil
+}
+
+// familyFilter converts --ipv4/--ipv6 flags into a family filter.
+// Setting both (or neither) means no restriction.
+func familyFilter(ipv4, ipv6 bool) []domain.AddressFamily {
+	switch {
+	case ipv4 && !ipv6:
+		return []domain.AddressFamily{domain.FamilyIPv4}
+	case ipv6 && !ipv4:
+		return []domain.AddressFamily{domain.FamilyIPv6}
+	default:
+		return nil
+	}
 }
 
 func parseSortField(s string) services.SortField {
@@ -79,6 +280,14 @@ func parseSortField(s string) services.SortField {
 		return services.SortByName
 	case "protocol":
 		return services.SortByProtocol
+	case "cpu":
+		return services.SortByCPU
+	case "rss", "mem", "memory":
+		return services.SortByMemory
+	case "fds":
+		return services.SortByFDs
+	case "uptime":
+		return services.SortByUptime
 	default:
 		return services.SortByPort
 	}
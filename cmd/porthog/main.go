@@ -3,8 +3,13 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/z1j1e/porthog/internal/adapters/process"
+	"github.com/z1j1e/porthog/internal/config"
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
 var (
@@ -13,8 +18,24 @@ var (
 	date    = "unknown"
 )
 
+var (
+	procRoot      string
+	socketBackend string
+	cacheTTL      time.Duration
+	debug         bool
+	redactor      *process.Redactor
+
+	// resolvers created by the command, whose cache statistics --debug
+	// reports
+	resolvers []*process.Resolver
+)
+
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if debug {
+		reportCacheStats()
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -24,9 +45,15 @@ var rootCmd = &cobra.Command{
 	Use:   "porthog",
 	Short: "Cross-platform port management CLI",
 	Long:  "porthog — find what's hogging your ports. List, kill, find free ports, and watch in real-time.",
+
+	PersistentPreRunE: applyConfig,
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&procRoot, "proc-root", "", "Read sockets and processes from the procfs mounted here, e.g. a host's /proc inside a container (Linux)")
+	rootCmd.PersistentFlags().StringVar(&socketBackend, "backend", "", "Where to read sockets from on Linux: auto, netlink or proc")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 0, "How long resolved process details are reused (default 5s)")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Report process cache statistics on exit")
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(killCmd)
@@ -42,3 +69,73 @@ var versionCmd = &cobra.Command{
 		fmt.Printf("porthog %s (commit: %s, built: %s)\n", version, commit, date)
 	},
 }
+
+// applyConfig fills in the global settings not given on the command line
+// from the config file and environment.
+func applyConfig(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !cmd.Flags().Changed("proc-root") {
+		procRoot = cfg.ProcRoot
+	}
+	if !cmd.Flags().Changed("backend") {
+		socketBackend = cfg.SocketBackend
+	}
+	if !cmd.Flags().Changed("cache-ttl") {
+		cacheTTL = cfg.ProcessCacheTTL()
+	} else if cacheTTL <= 0 {
+		return fmt.Errorf("invalid --cache-ttl %s (must be positive)", cacheTTL)
+	}
+	if _, err := ports.ParseBackend(socketBackend); err != nil {
+		return err
+	}
+	if redactor, err = process.NewRedactor(cfg.RedactPatterns); err != nil {
+		return err
+	}
+	if procRoot != "/proc" {
+		if fi, err := os.Stat(procRoot); err != nil || !fi.IsDir() {
+			return fmt.Errorf("proc root %q is not a directory", procRoot)
+		}
+	}
+	return nil
+}
+
+// enumeratorOptions adds the global procfs and backend settings to opts.
+func enumeratorOptions(opts ports.EnumeratorOptions) ports.EnumeratorOptions {
+	opts.ProcRoot = procRoot
+	opts.Backend, _ = ports.ParseBackend(socketBackend)
+	return opts
+}
+
+// newResolver creates a resolver for the global procfs root. With details
+// set it also reads ancestry and command lines, the latter masked by the
+// configured redaction rules.
+func newResolver(details bool) *process.Resolver {
+	return newResolverFrom(resolverOptions(details))
+}
+
+func resolverOptions(details bool) process.Options {
+	return process.Options{
+		ProcRoot: procRoot, Cmdline: details, Ancestry: details, Redactor: redactor,
+		CacheTTL: cacheTTL,
+	}
+}
+
+// newResolverFrom creates a resolver with opts, tracked for --debug.
+func newResolverFrom(opts process.Options) *process.Resolver {
+	r := process.NewResolverWithOptions(opts)
+	resolvers = append(resolvers, r)
+	return r
+}
+
+// reportCacheStats prints the cache statistics of every resolver the
+// command used to stderr.
+func reportCacheStats() {
+	for _, r := range resolvers {
+		s := r.CacheStats()
+		fmt.Fprintf(os.Stderr, "debug: process cache: %d hits, %d misses, %d evictions, %d entries (ttl %s)\n",
+			s.Hits, s.Misses, s.Evictions, s.Entries, cacheTTL)
+	}
+}
@@ -10,12 +10,21 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/z1j1e/porthog/internal/adapters/platform"
-	"github.com/z1j1e/porthog/internal/adapters/process"
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 	"github.com/z1j1e/porthog/internal/core/services"
 	"github.com/z1j1e/porthog/internal/tui/watch"
 )
 
-var watchInterval time.Duration
+var (
+	watchInterval time.Duration
+	watchIPv4     bool
+	watchIPv6     bool
+	watchStates   []string
+	watchIfaces   []string
+	watchApps     []string
+	watchEvents   bool
+)
 
 var watchCmd = &cobra.Command{
 	Use:   "watch",
@@ -25,17 +34,45 @@ var watchCmd = &cobra.Command{
 			return fmt.Errorf("watch requires a TTY; use --ci-snapshot for non-interactive mode")
 		}
 
-		enum := platform.NewEnumerator()
-		resolver := process.NewResolver()
-		svc := services.NewWatchPortsService(enum, resolver)
+		filter, err := buildWatchFilter()
+		if err != nil {
+			return err
+		}
+
+		watcher := platform.NewWatcher(enumeratorOptions(ports.EnumeratorOptions{Incremental: true}))
+		resolver := newResolver(true)
+		svc := services.NewWatchPortsServiceWithWatcher(watcher, resolver)
+		if watchEvents {
+			svc.SetProcessMonitor(platform.NewProcessMonitor())
+		}
 
-		model := watch.New(svc, nil, watchInterval)
+		model := watch.New(svc, filter, watchInterval, watchEvents)
 		p := tea.NewProgram(model, tea.WithAltScreen())
-		_, err := p.Run()
+		_, err = p.Run()
 		return err
 	},
 }
 
 func init() {
 	watchCmd.Flags().DurationVar(&watchInterval, "interval", 1*time.Second, "Refresh interval")
+	watchCmd.Flags().BoolVarP(&watchIPv4, "ipv4", "4", false, "Show only IPv4 sockets")
+	watchCmd.Flags().BoolVarP(&watchIPv6, "ipv6", "6", false, "Show only IPv6 sockets")
+	watchCmd.Flags().StringSliceVar(&watchStates, "state", nil, "Show only sockets in these states")
+	watchCmd.Flags().StringSliceVar(&watchIfaces, "iface", nil, "Show only sockets reachable through these network interfaces")
+	watchCmd.Flags().StringSliceVar(&watchApps, "app", nil, "Show only sockets of these apps (runtime or part of the APP label, e.g. java, uvicorn)")
+	watchCmd.Flags().BoolVar(&watchEvents, "events", true, "Show sockets opening and closing, and refresh when their processes exit, as it happens (Linux, needs CAP_NET_ADMIN)")
+}
+
+// buildWatchFilter builds the filter for the watch flags.
+func buildWatchFilter() (*domain.Filter, error) {
+	states, err := parseStates(watchStates)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Filter{
+		Families:   familyFilter(watchIPv4, watchIPv6),
+		States:     states,
+		Interfaces: watchIfaces,
+		Apps:       watchApps,
+	}, nil
 }
@@ -40,6 +40,7 @@ func (e *Enumerator) List(ctx context.Context, filter *domain.Filter) (*domain.P
 		Data: bindings, Warnings: warnings,
 	}, nil
 }
+
 // PLACEHOLDER_DARWIN_LSOF
 
 func runLsof(ctx context.Context) ([]domain.PortBinding, error) {
@@ -55,6 +56,7 @@ func parseLsofOutput(output string) ([]domain.PortBinding, error) {
 	var bindings []domain.PortBinding
 	var pid int32
 	var pname string
+	var family domain.AddressFamily
 
 	scanner := bufio.NewScanner(strings.NewReader(output))
 	for scanner.Scan() {
@@ -69,8 +71,10 @@ func parseLsofOutput(output string) ([]domain.PortBinding, error) {
 			pid = int32(p)
 		case 'c':
 			pname = val
+		case 't':
+			family = parseFamily(val)
 		case 'n':
-			b, ok := parseNameField(val, pid, pname)
+			b, ok := parseNameField(val, pid, pname, family)
 			if ok {
 				bindings = append(bindings, b)
 			}
@@ -79,7 +83,19 @@ func parseLsofOutput(output string) ([]domain.PortBinding, error) {
 	return bindings, nil
 }
 
-func parseNameField(name string, pid int32, pname string) (domain.PortBinding, bool) {
+// parseFamily maps the lsof type field ("IPv4"/"IPv6") to an address family.
+func parseFamily(t string) domain.AddressFamily {
+	switch t {
+	case "IPv4":
+		return domain.FamilyIPv4
+	case "IPv6":
+		return domain.FamilyIPv6
+	default:
+		return domain.FamilyUnknown
+	}
+}
+
+func parseNameField(name string, pid int32, pname string, family domain.AddressFamily) (domain.PortBinding, bool) {
 	// lsof -F n format: "host:port" or "host:port->remote:port"
 	parts := strings.SplitN(name, "->", 2)
 	localIP, localPort, ok := parseHostPort(parts[0])
@@ -87,8 +103,16 @@ func parseNameField(name string, pid int32, pname string) (domain.PortBinding, b
 		return domain.PortBinding{}, false
 	}
 
+	if family == domain.FamilyUnknown {
+		family = domain.FamilyOf(localIP)
+	}
+	if family == domain.FamilyIPv6 && localIP.Equal(net.IPv4zero) {
+		localIP = net.IPv6unspecified
+	}
+
 	b := domain.PortBinding{
 		Protocol:  domain.TCP,
+		Family:    family,
 		LocalIP:   localIP,
 		LocalPort: localPort,
 		State:     domain.StateListen,
@@ -112,7 +136,7 @@ func parseHostPort(s string) (net.IP, uint16, bool) {
 	if idx < 0 {
 		return nil, 0, false
 	}
-	host := s[:idx]
+	host := strings.Trim(s[:idx], "[]")
 	port, err := strconv.ParseUint(s[idx+1:], 10, 16)
 	if err != nil {
 		return nil, 0, false
@@ -121,5 +145,5 @@ func parseHostPort(s string) (net.IP, uint16, bool) {
 	if ip == nil {
 		ip = net.IPv4zero
 	}
-	return ip, uint16(port), true
+	return domain.NormalizeIP(ip), uint16(port), true
 }
@@ -65,3 +65,30 @@ func TestDarwinEnumerator_ProtocolFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLsofOutput_IPv6TypeField(t *testing.T) {
+	out := "p321\ncnode\nf20\ntIPv4\nPTCP\nn*:3000\nTST=LISTEN\nf21\ntIPv6\nPTCP\nn*:3000\nTST=LISTEN\nf22\ntIPv6\nPTCP\nn[::1]:8080\nTST=LISTEN\n"
+	bindings, err := parseLsofOutput(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		family domain.AddressFamily
+		ip     net.IP
+		port   uint16
+	}{
+		{domain.FamilyIPv4, net.IPv4zero, 3000},
+		{domain.FamilyIPv6, net.IPv6unspecified, 3000},
+		{domain.FamilyIPv6, net.IPv6loopback, 8080},
+	}
+	if len(bindings) != len(want) {
+		t.Fatalf("expected %d bindings, got %d: %+v", len(want), len(bindings), bindings)
+	}
+	for i, w := range want {
+		b := bindings[i]
+		if b.Family != w.family || !b.LocalIP.Equal(w.ip) || b.LocalPort != w.port || b.PID != 321 {
+			t.Errorf("binding %d = %s %s:%d pid %d, want %s %s:%d pid 321",
+				i, b.Family, b.LocalIP, b.LocalPort, b.PID, w.family, w.ip, w.port)
+		}
+	}
+}
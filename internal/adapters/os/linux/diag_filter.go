@@ -0,0 +1,121 @@
+//go:build linux
+
+package linux
+
+import (
+	"encoding/binary"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// inet_diag bytecode opcodes (include/uapi/linux/inet_diag.h).
+const (
+	inetDiagReqBytecode = 1
+
+	inetDiagBCJmp = 1
+	inetDiagBCSGE = 2
+	inetDiagBCSLE = 3
+
+	allStates = 0xFFFFFFFF
+)
+
+// linuxStates maps the kernel's TCP_* state numbers (include/net/tcp_states.h),
+// which sock_diag and /proc/net also use for UDP and Unix sockets.
+var linuxStates = [...]domain.SocketState{
+	0x01: domain.StateEstablished,
+	0x02: domain.StateSynSent,
+	0x03: domain.StateSynRecv,
+	0x04: domain.StateFinWait1,
+	0x05: domain.StateFinWait2,
+	0x06: domain.StateTimeWait,
+	0x07: domain.StateClosed,
+	0x08: domain.StateCloseWait,
+	0x09: domain.StateLastAck,
+	0x0A: domain.StateListen,
+	0x0B: domain.StateClosing,
+	0x0C: domain.StateNewSynRecv,
+	0x0D: domain.StateBoundInactive,
+}
+
+func mapLinuxState(st uint8) domain.SocketState {
+	if int(st) < len(linuxStates) {
+		return linuxStates[st]
+	}
+	return domain.StateUnknown
+}
+
+// stateMask translates filter states into the idiag_states/udiag_states
+// bitmask (bit N selects kernel state N).
+func stateMask(filter *domain.Filter) uint32 {
+	if filter == nil || len(filter.States) == 0 {
+		return allStates
+	}
+	var mask uint32
+	for _, want := range filter.States {
+		if want == domain.StateUnknown {
+			return allStates
+		}
+		for k, st := range linuxStates {
+			if st == want {
+				mask |= 1 << k
+			}
+		}
+	}
+	return mask
+}
+
+// portRanges returns the inclusive local-port ranges a filter accepts. A nil
+// slice means ports are unrestricted; ok is false when no port can match.
+func portRanges(filter *domain.Filter) (ranges []domain.PortRange, ok bool) {
+	if filter == nil {
+		return nil, true
+	}
+	if len(filter.Ports) == 0 {
+		if filter.PortRange != nil {
+			return []domain.PortRange{*filter.PortRange}, true
+		}
+		return nil, true
+	}
+	for _, p := range filter.Ports {
+		if filter.PortRange == nil || filter.PortRange.Contains(p) {
+			ranges = append(ranges, domain.PortRange{Start: p, End: p})
+		}
+	}
+	return ranges, len(ranges) > 0
+}
+
+// portBytecode compiles port ranges into INET_DIAG_REQ_BYTECODE that accepts
+// a socket whose local port falls in any of them. Each range becomes:
+//
+//	S_GE start  (yes: next op, no: next range)
+//	S_LE end    (yes: next op, no: next range)
+//	JMP  accept
+//
+// The JMP is needed because an op's "yes" offset is a single byte and cannot
+// reach the end of a long program. Jumping to len+4 rejects the socket.
+func portBytecode(ranges []domain.PortRange) []byte {
+	const condLen = 20
+	total := condLen * len(ranges)
+	bc := make([]byte, total)
+	for i, r := range ranges {
+		off := i * condLen
+		last := i == len(ranges)-1
+
+		geNo, leNo := condLen, condLen-8
+		if last {
+			geNo, leNo = condLen+4, condLen-8+4
+		}
+		putBCOp(bc[off:], inetDiagBCSGE, 8, uint16(geNo))
+		putBCOp(bc[off+4:], 0, 0, r.Start)
+		putBCOp(bc[off+8:], inetDiagBCSLE, 8, uint16(leNo))
+		putBCOp(bc[off+12:], 0, 0, r.End)
+		putBCOp(bc[off+16:], inetDiagBCJmp, 4, uint16(total-off-16))
+	}
+	return bc
+}
+
+func putBCOp(b []byte, code, yes uint8, no uint16) {
+	b[0] = code
+	b[1] = yes
+	binary.LittleEndian.PutUint16(b[2:4], no)
+}
@@ -7,149 +7,480 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"syscall"
 
 	"golang.org/x/sys/unix"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
+const defaultProcRoot = "/proc"
+
 // Enumerator implements port enumeration on Linux.
-type Enumerator struct{}
+type Enumerator struct {
+	opts     ports.EnumeratorOptions
+	procRoot string
+	euid     int
+	owners   *ownerCache // set when opts.Incremental
+}
+
+func NewEnumerator() *Enumerator {
+	return NewEnumeratorWithOptions(ports.EnumeratorOptions{})
+}
+
+// NewEnumeratorWithOptions creates an Enumerator that performs the optional
+// work selected in opts.
+func NewEnumeratorWithOptions(opts ports.EnumeratorOptions) *Enumerator {
+	e := &Enumerator{opts: opts, procRoot: defaultProcRoot, euid: os.Geteuid()}
+	if opts.ProcRoot != "" {
+		e.procRoot = filepath.Clean(opts.ProcRoot)
+	}
+	if opts.Incremental {
+		e.owners = newOwnerCache(e.procRoot)
+	}
+	return e
+}
 
-func NewEnumerator() *Enumerator { return &Enumerator{} }
+// foreignRoot reports whether procfs is read from somewhere other than
+// /proc, in which case it may describe another system than the one
+// porthog's own sockets and threads live in.
+func (e *Enumerator) foreignRoot() bool { return e.procRoot != defaultProcRoot }
+
+// netlinkEnabled reports whether sockets are dumped with sock_diag.
+func (e *Enumerator) netlinkEnabled() bool {
+	switch e.opts.Backend {
+	case ports.BackendNetlink:
+		return true
+	case ports.BackendProc:
+		return false
+	default:
+		return !e.foreignRoot()
+	}
+}
+
+// hostNet returns the socket tables of the namespace listed by default. In
+// a foreign procfs that is the namespace of its PID 1 rather than
+// <root>/net, which follows the reading process.
+func (e *Enumerator) hostNet() string {
+	if !e.foreignRoot() {
+		return "/proc/net"
+	}
+	if dir := filepath.Join(e.procRoot, "1", "net"); dirExists(dir) {
+		return dir
+	}
+	return filepath.Join(e.procRoot, "net")
+}
+
+func dirExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// inetSource describes one sock_diag dump and its /proc/net fallback.
+type inetSource struct {
+	proto    domain.Protocol
+	family   domain.AddressFamily
+	ipproto  uint8
+	afamily  uint8
+	procFile string // file name under /proc/net
+	label    string
+	procOnly bool // no sock_diag handler exists for this protocol
+}
+
+var inetSources = []inetSource{
+	{domain.TCP, domain.FamilyIPv4, unix.IPPROTO_TCP, unix.AF_INET, "tcp", "TCP", false},
+	{domain.TCP, domain.FamilyIPv6, unix.IPPROTO_TCP, unix.AF_INET6, "tcp6", "TCP6", false},
+	{domain.UDP, domain.FamilyIPv4, unix.IPPROTO_UDP, unix.AF_INET, "udp", "UDP", false},
+	{domain.UDP, domain.FamilyIPv6, unix.IPPROTO_UDP, unix.AF_INET6, "udp6", "UDP6", false},
+	{domain.Raw, domain.FamilyIPv4, unix.IPPROTO_RAW, unix.AF_INET, "raw", "RAW", false},
+	{domain.Raw, domain.FamilyIPv6, unix.IPPROTO_RAW, unix.AF_INET6, "raw6", "RAW6", false},
+	{domain.ICMP, domain.FamilyIPv4, unix.IPPROTO_ICMP, unix.AF_INET, "icmp", "ICMP", true},
+	{domain.ICMP, domain.FamilyIPv6, unix.IPPROTO_ICMPV6, unix.AF_INET6, "icmp6", "ICMP6", true},
+}
+
+// sockEntry pairs a parsed binding with the socket inode used for PID lookup.
+type sockEntry struct {
+	binding domain.PortBinding
+	inode   uint64
+	cookie  uint64 // sock_diag socket cookie, 0 when read from procfs
+}
 
 func (e *Enumerator) List(ctx context.Context, filter *domain.Filter) (*domain.PartialResult[[]domain.PortBinding], error) {
-	var bindings []domain.PortBinding
+	var entries []sockEntry
 	var warnings []string
 
-	wantTCP := filter == nil || len(filter.Protocols) == 0 || hasProto(filter.Protocols, domain.TCP)
-	wantUDP := filter == nil || len(filter.Protocols) == 0 || hasProto(filter.Protocols, domain.UDP)
+	targets, err := e.namespaces()
+	if err != nil {
+		return nil, err
+	}
+	if targets == nil {
+		// With a foreign procfs only an explicitly requested netlink dump
+		// reads the namespace porthog runs in.
+		local := !e.foreignRoot() || e.opts.Backend == ports.BackendNetlink
+		entries, warnings = e.collect(ctx, filter, e.hostNet(), local)
+	}
+	for i := range targets {
+		se, w := e.collectNamespace(ctx, filter, &targets[i])
+		entries = append(entries, se...)
+		warnings = append(warnings, w...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries = prefilter(entries, filter)
 
-	if wantTCP {
-		tcp, err := enumNetlink(unix.IPPROTO_TCP)
-		if err != nil {
-			tcp, err = parseProcNet("/proc/net/tcp", domain.TCP)
-			if err != nil {
-				warnings = append(warnings, "TCP enumeration failed: "+err.Error())
-			}
+	// Batch-map socket inodes to PIDs via /proc/<pid>/fd in a single pass.
+	// Socket inodes are unique across namespaces, so one scan covers them all.
+	inodes := make([]uint64, len(entries))
+	for i, e := range entries {
+		inodes[i] = e.inode
+	}
+	var inodeOwners map[uint64][]domain.SocketOwner
+	var denied int
+	if e.owners != nil {
+		inodeOwners, denied = e.owners.lookup(inodes)
+	} else {
+		var pids []int32
+		if filter != nil {
+			pids = filter.PIDs
 		}
-		bindings = append(bindings, tcp...)
+		inodeOwners, denied = mapInodesToOwners(e.procRoot, inodes, pids)
 	}
+	restricted := e.restricted(denied)
 
-	if wantUDP {
-		udp, err := enumNetlink(unix.IPPROTO_UDP)
-		if err != nil {
-			udp, err = parseProcNet("/proc/net/udp", domain.UDP)
-			if err != nil {
-				warnings = append(warnings, "UDP enumeration failed: "+err.Error())
+	var bindings []domain.PortBinding
+	hidden := 0
+	for _, se := range entries {
+		b := e.attribute(se, inodeOwners[se.inode], restricted)
+		if filter.Matches(&b) {
+			if b.OwnerKind == domain.OwnerHidden {
+				hidden++
 			}
+			bindings = append(bindings, b)
 		}
-		bindings = append(bindings, udp...)
+	}
+	if hidden > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d sockets are held by processes hidden from this user; run as root to attribute them", hidden))
 	}
 
-	if filter != nil {
-		var filtered []domain.PortBinding
-		for i := range bindings {
-			if filter.Matches(&bindings[i]) {
-				filtered = append(filtered, bindings[i])
-			}
+	return &domain.PartialResult[[]domain.PortBinding]{
+		Data: bindings, Warnings: warnings, Partial: hidden > 0, DeniedCount: hidden,
+	}, nil
+}
+
+// prefilter drops non-matching sockets before the /proc scan. PIDs are
+// unknown at this point, so that part of the filter is applied afterwards.
+func prefilter(entries []sockEntry, filter *domain.Filter) []sockEntry {
+	if filter == nil {
+		return entries
+	}
+	pre := *filter
+	pre.PIDs = nil
+	kept := entries[:0]
+	for _, e := range entries {
+		if pre.Matches(&e.binding) {
+			kept = append(kept, e)
 		}
-		bindings = filtered
 	}
+	return kept
+}
 
-	return &domain.PartialResult[[]domain.PortBinding]{Data: bindings, Warnings: warnings}, nil
+// restricted reports whether some processes' descriptors could not be
+// read, denied of them during the scan or any under hidepid.
+func (e *Enumerator) restricted(denied int) bool {
+	return denied > 0 || (e.euid != 0 && procHidesPIDs(e.procRoot))
 }
 
-// enumNetlink uses SOCK_DIAG netlink to enumerate sockets.
-func enumNetlink(proto uint8) ([]domain.PortBinding, error) {
-	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM, unix.NETLINK_SOCK_DIAG)
-	if err != nil {
-		return nil, err
+// attribute returns the binding of se held by owners, or explains why no
+// process holds it.
+func (e *Enumerator) attribute(se sockEntry, owners []domain.SocketOwner, restricted bool) domain.PortBinding {
+	b := se.binding
+	if len(owners) > 0 {
+		b.Owners = owners
+		b.PID = owners[0].PID
+		b.OwnerKind = domain.OwnerProcess
+	} else {
+		b.OwnerKind = classifyUnowned(se, restricted, e.euid)
 	}
-	defer unix.Close(fd)
-
-	req := buildInetDiagReq(proto)
-	sa := &unix.SockaddrNetlink{Family: unix.AF_NETLINK}
-	if err := unix.Sendto(fd, req, 0, sa); err != nil {
-		return nil, err
+	if b.OwnerKind == domain.OwnerNone {
+		// Released sockets report UID 0 whoever created them.
+		b.UID, b.HasUID = 0, false
 	}
+	return b
+}
 
-	type bindingWithInode struct {
-		binding domain.PortBinding
-		inode   uint64
+// classifyUnowned explains a socket no readable /proc/<pid>/fd refers to.
+// Inode 0 means the socket has been released (TIME_WAIT, request sockets).
+// Otherwise it is held either by a process we were not allowed to inspect
+// or by no process at all, i.e. by the kernel. Our own sockets are never
+// hidden from us, so those go to the kernel too.
+func classifyUnowned(e sockEntry, restricted bool, euid int) domain.OwnerKind {
+	switch {
+	case e.inode == 0:
+		return domain.OwnerNone
+	case restricted && !(e.binding.HasUID && int(e.binding.UID) == euid):
+		return domain.OwnerHidden
+	default:
+		return domain.OwnerKernel
 	}
-	var entries []bindingWithInode
-	buf := make([]byte, 65536)
-	for {
-		n, _, err := unix.Recvfrom(fd, buf, 0)
+}
+
+// namespaces returns the network namespaces selected by the options, or nil
+// to enumerate only the current one without labelling it.
+func (e *Enumerator) namespaces() ([]netnsTarget, error) {
+	switch {
+	case e.opts.Netns != "":
+		t, err := resolveNamespace(e.procRoot, e.opts.Netns)
 		if err != nil {
-			break
+			return nil, err
 		}
-		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		return []netnsTarget{t}, nil
+	case e.opts.AllNamespaces:
+		return discoverNamespaces(e.procRoot)
+	default:
+		return nil, nil
+	}
+}
+
+// collectNamespace enumerates one namespace and labels its sockets. Foreign
+// namespaces are entered with setns(2) so sock_diag can be used; without
+// CAP_SYS_ADMIN the representative process's /proc/<pid>/net is parsed
+// instead.
+func (e *Enumerator) collectNamespace(ctx context.Context, filter *domain.Filter, t *netnsTarget) ([]sockEntry, []string) {
+	var entries []sockEntry
+	var warnings []string
+	if t.host {
+		entries, warnings = e.collect(ctx, filter, "/proc/net", true)
+	} else {
+		err := inNamespace(t.nsPath, func() {
+			entries, warnings = e.collect(ctx, filter, "/proc/thread-self/net", true)
+		})
 		if err != nil {
-			break
+			procNet := t.procNet(e.procRoot)
+			if procNet == "" {
+				return nil, []string{fmt.Sprintf("network namespace %s: %v", t.ns.String(), err)}
+			}
+			entries, warnings = e.collect(ctx, filter, procNet, false)
+		}
+	}
+
+	ns := t.ns
+	for i := range entries {
+		entries[i].binding.Netns = &ns
+	}
+	return entries, warnings
+}
+
+// collect enumerates the sockets in procNet, or of the namespace the calling
+// thread is in when local is true, which allows sock_diag dumps and naming
+// interfaces. A dump that fails for any other reason than sock_diag not
+// supporting the protocol is reported as a warning.
+func (e *Enumerator) collect(ctx context.Context, filter *domain.Filter, procNet string, local bool) ([]sockEntry, []string) {
+	var entries []sockEntry
+	var warnings []string
+	useNetlink := local && e.netlinkEnabled()
+
+	// Push state and port constraints into the kernel so busy hosts don't
+	// dump (and then inode-scan) sockets we would discard anyway.
+	ranges, portsOK := portRanges(filter)
+	for _, src := range inetSources {
+		if !portsOK || !wantSource(filter, src) {
+			continue
 		}
-		done := false
-		for _, msg := range msgs {
-			if msg.Header.Type == syscall.NLMSG_DONE {
-				done = true
-				break
+		var se []sockEntry
+		err := errNetlinkSkipped
+		if useNetlink && !src.procOnly {
+			se, err = e.enumNetlink(ctx, src, filter, ranges)
+		}
+		if err != nil {
+			w, fallBack := e.dumpFailed(src.label, err)
+			if w != "" {
+				warnings = append(warnings, w)
 			}
-			if msg.Header.Type == syscall.NLMSG_ERROR {
-				return nil, fmt.Errorf("netlink error response")
+			if !fallBack {
+				continue
 			}
-			if len(msg.Data) >= 72 {
-				b, ino := parseInetDiagMsg(msg.Data, proto)
-				entries = append(entries, bindingWithInode{binding: b, inode: ino})
+			se, err = parseProcNet(src, filepath.Join(procNet, src.procFile), e.opts.Metrics)
+			if err != nil {
+				// A missing *6 file just means IPv6 is disabled in this kernel.
+				if src.family == domain.FamilyIPv6 && errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				warnings = append(warnings, src.label+" enumeration failed: "+err.Error())
 			}
 		}
-		if done {
-			break
+		setIPProtocol(src, se)
+		entries = append(entries, se...)
+	}
+
+	if wantSCTP(filter) && portsOK {
+		var se []sockEntry
+		err := errNetlinkSkipped
+		if useNetlink {
+			se, err = enumSCTPNetlink(ctx, filter)
+		}
+		if err != nil {
+			w, fallBack := e.dumpFailed("SCTP", err)
+			if w != "" {
+				warnings = append(warnings, w)
+			}
+			if fallBack {
+				se, err = parseProcSCTP(filepath.Join(procNet, "sctp"))
+				// No /proc/net/sctp just means the sctp module is not loaded.
+				if err != nil && !errors.Is(err, fs.ErrNotExist) {
+					warnings = append(warnings, "SCTP enumeration failed: "+err.Error())
+				}
+			}
 		}
+		entries = append(entries, se...)
 	}
 
-	// Batch-map socket inodes to PIDs via /proc/<pid>/fd
-	inodes := make([]uint64, len(entries))
-	for i, e := range entries {
-		inodes[i] = e.inode
+	if wantUnix(filter) {
+		var se []sockEntry
+		err := errNetlinkSkipped
+		if useNetlink {
+			se, err = enumUnixNetlink(ctx, filter)
+		}
+		if err != nil {
+			w, fallBack := e.dumpFailed("Unix socket", err)
+			if w != "" {
+				warnings = append(warnings, w)
+			}
+			if fallBack {
+				se, err = parseProcNetUnix(filepath.Join(procNet, "unix"))
+				if err != nil {
+					warnings = append(warnings, "Unix socket enumeration failed: "+err.Error())
+				}
+			}
+		}
+		entries = append(entries, se...)
 	}
-	inodePIDMap := mapInodesToPIDs(inodes)
 
-	bindings := make([]domain.PortBinding, len(entries))
-	for i, e := range entries {
-		b := e.binding
-		if pid, ok := inodePIDMap[e.inode]; ok {
-			b.PID = pid
+	// Interface indexes are per namespace, so they can only be named from
+	// inside it, not when reading a foreign namespace's tables from outside.
+	if local {
+		labelInterfaces(entries)
+	}
+	return entries, warnings
+}
+
+// dumpFailed handles a sock_diag dump that failed or was not attempted. It
+// returns the warning to report, if any, and whether to read procfs
+// instead. The netlink backend only reads procfs for tables sock_diag
+// cannot dump at all, such as those of a namespace that cannot be entered.
+func (e *Enumerator) dumpFailed(label string, err error) (string, bool) {
+	if e.opts.Backend == ports.BackendNetlink && !errors.Is(err, errNetlinkSkipped) {
+		return label + " sock_diag dump failed: " + err.Error(), false
+	}
+	return fallbackWarning(label, err), true
+}
+
+func wantSource(filter *domain.Filter, src inetSource) bool {
+	if !src.proto.HasPorts() {
+		return wantPortless(filter, src.proto) && wantFamily(filter, src.family)
+	}
+	if filter == nil {
+		return true
+	}
+	if len(filter.Protocols) > 0 && !hasProto(filter.Protocols, src.proto) {
+		return false
+	}
+	return wantFamily(filter, src.family)
+}
+
+// wantPortless reports whether raw or ICMP sockets were explicitly requested.
+// Like Unix sockets they are opt-in, and a port filter rules them out.
+func wantPortless(filter *domain.Filter, proto domain.Protocol) bool {
+	return filter != nil && hasProto(filter.Protocols, proto) &&
+		len(filter.Ports) == 0 && filter.PortRange == nil
+}
+
+// wantFamily reports whether a table of family can hold sockets filter
+// wants. IPv6 tables also hold dual-stack sockets with IPv4-mapped
+// addresses, which are reported as IPv4, so they are read for IPv4 too and
+// the bindings filtered on their own family.
+func wantFamily(filter *domain.Filter, family domain.AddressFamily) bool {
+	if filter == nil || len(filter.Families) == 0 || hasFamily(filter.Families, family) {
+		return true
+	}
+	return family == domain.FamilyIPv6 && hasFamily(filter.Families, domain.FamilyIPv4)
+}
+
+// enumNetlink uses SOCK_DIAG netlink to enumerate sockets.
+func (e *Enumerator) enumNetlink(ctx context.Context, src inetSource, filter *domain.Filter, ranges []domain.PortRange) ([]sockEntry, error) {
+	var ext uint8
+	if e.opts.Metrics && src.proto == domain.TCP {
+		ext = 1 << (inetDiagInfo - 1)
+	}
+
+	var entries []sockEntry
+	reset := func() { entries = entries[:0] }
+	err := netlinkDump(ctx, buildInetDiagReq(src, ext, stateMask(filter), ranges), reset, func(data []byte) {
+		if len(data) >= inetDiagMsgLen {
+			b, ino := parseInetDiagMsg(data, src.proto)
+			if e.opts.Metrics {
+				b.Metrics = parseDiagMetrics(data)
+			}
+			entries = append(entries, sockEntry{binding: b, inode: ino, cookie: diagCookie(data)})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// setIPProtocol records the IP protocol of raw and ICMP sockets. The kernel
+// reports a raw socket's protocol in the port field, which is cleared; an
+// ICMP socket's port field is its echo identifier and is kept.
+func setIPProtocol(src inetSource, entries []sockEntry) {
+	for i := range entries {
+		b := &entries[i].binding
+		switch src.proto {
+		case domain.Raw:
+			b.IPProtocol, b.LocalPort = uint8(b.LocalPort), 0
+		case domain.ICMP:
+			b.IPProtocol = src.ipproto
 		}
-		bindings[i] = b
 	}
-	return bindings, nil
 }
 
-func buildInetDiagReq(proto uint8) []byte {
-	const hdrLen = 16
+func buildInetDiagReq(src inetSource, ext uint8, states uint32, ranges []domain.PortRange) []byte {
 	const msgLen = 56
-	buf := make([]byte, hdrLen+msgLen)
-	binary.LittleEndian.PutUint32(buf[0:4], uint32(hdrLen+msgLen))
-	binary.LittleEndian.PutUint16(buf[4:6], 20) // SOCK_DIAG_BY_FAMILY
-	binary.LittleEndian.PutUint16(buf[6:8], unix.NLM_F_DUMP|unix.NLM_F_REQUEST)
-	buf[hdrLen] = unix.AF_INET
-	buf[hdrLen+1] = proto
-	binary.LittleEndian.PutUint32(buf[hdrLen+4:hdrLen+8], 0xFFFFFFFF) // all states
+	var bc []byte
+	attrLen := 0
+	if len(ranges) > 0 {
+		bc = portBytecode(ranges)
+		attrLen = 4 + len(bc)
+	}
+	buf := newDiagReq(msgLen + attrLen)
+	buf[nlmsgHdrLen] = src.afamily
+	buf[nlmsgHdrLen+1] = src.ipproto
+	buf[nlmsgHdrLen+2] = ext
+	if src.proto == domain.Raw {
+		// sdiag_raw_protocol: raw_diag dumps sockets of every protocol.
+		buf[nlmsgHdrLen+3] = unix.IPPROTO_RAW
+	}
+	binary.LittleEndian.PutUint32(buf[nlmsgHdrLen+4:nlmsgHdrLen+8], states)
+	if bc != nil {
+		attr := buf[nlmsgHdrLen+msgLen:]
+		binary.LittleEndian.PutUint16(attr[0:2], uint16(attrLen))
+		binary.LittleEndian.PutUint16(attr[2:4], inetDiagReqBytecode)
+		copy(attr[4:], bc)
+	}
 	return buf
 }
 
-func parseInetDiagMsg(data []byte, proto uint8) (domain.PortBinding, uint64) {
-	p := domain.TCP
-	if proto == unix.IPPROTO_UDP {
-		p = domain.UDP
-	}
+func parseInetDiagMsg(data []byte, proto domain.Protocol) (domain.PortBinding, uint64) {
 	// inet_diag_msg layout:
 	// [0] family, [1] state, [2] timer, [3] retrans
 	// [4-5] sport (big-endian), [6-7] dport (big-endian)
@@ -157,33 +488,39 @@ func parseInetDiagMsg(data []byte, proto uint8) (domain.PortBinding, uint64) {
 	// [40-43] interface, [44-51] cookie
 	// [52-55] expires, [56-59] rqueue, [60-63] wqueue
 	// [64-67] uid, [68-71] inode
+	ipLen := net.IPv4len
+	if data[0] == unix.AF_INET6 {
+		ipLen = net.IPv6len
+	}
 	state := data[1]
 	srcPort := binary.BigEndian.Uint16(data[4:6])
 	dstPort := binary.BigEndian.Uint16(data[6:8])
-	srcIP := net.IP(make([]byte, 4))
-	copy(srcIP, data[8:12])
-	dstIP := net.IP(make([]byte, 4))
-	copy(dstIP, data[24:28])
+	srcIP := net.IP(make([]byte, ipLen))
+	copy(srcIP, data[8:8+ipLen])
+	dstIP := net.IP(make([]byte, ipLen))
+	copy(dstIP, data[24:24+ipLen])
+	uid := binary.LittleEndian.Uint32(data[64:68])
 	inode := binary.LittleEndian.Uint32(data[68:72])
-	return domain.PortBinding{
-		Protocol: p, LocalIP: srcIP, LocalPort: srcPort,
-		RemoteIP: dstIP, RemotePort: dstPort,
-		State: mapLinuxState(state),
-	}, uint64(inode)
+	b := newBinding(proto, srcIP, srcPort, dstIP, dstPort, state)
+	b.UID, b.HasUID = uid, true
+	b.IfIndex = binary.LittleEndian.Uint32(data[40:44])
+	return b, uint64(inode)
 }
 
-func parseProcNet(path string, proto domain.Protocol) ([]domain.PortBinding, error) {
+// diagCookie returns the idiag_cookie of an inet_diag_msg, which identifies
+// a socket for as long as it exists.
+func diagCookie(data []byte) uint64 {
+	return binary.LittleEndian.Uint64(data[44:52])
+}
+
+func parseProcNet(src inetSource, path string, metrics bool) ([]sockEntry, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	type entryWithInode struct {
-		binding domain.PortBinding
-		inode   uint64
-	}
-	var entries []entryWithInode
+	var entries []sockEntry
 	scanner := bufio.NewScanner(f)
 	scanner.Scan() // skip header
 	for scanner.Scan() {
@@ -193,37 +530,36 @@ func parseProcNet(path string, proto domain.Protocol) ([]domain.PortBinding, err
 		}
 		localIP, localPort := parseHexAddr(fields[1])
 		remoteIP, remotePort := parseHexAddr(fields[2])
-		st, _ := strconv.ParseUint(fields[3], 16, 8)
-		inode, _ := strconv.ParseUint(fields[9], 10, 64)
-		entries = append(entries, entryWithInode{
-			binding: domain.PortBinding{
-				Protocol: proto, LocalIP: localIP, LocalPort: localPort,
-				RemoteIP: remoteIP, RemotePort: remotePort,
-				State: mapLinuxState(uint8(st)),
-			},
-			inode: inode,
-		})
+		st, stErr := strconv.ParseUint(fields[3], 16, 8)
+		inode, inoErr := strconv.ParseUint(fields[9], 10, 64)
+		if localIP == nil || remoteIP == nil || stErr != nil || inoErr != nil {
+			continue // torn or otherwise malformed line
+		}
+		b := newBinding(src.proto, localIP, localPort, remoteIP, remotePort, uint8(st))
+		if uid, err := strconv.ParseUint(fields[7], 10, 32); err == nil {
+			b.UID, b.HasUID = uint32(uid), true
+		}
+		if metrics {
+			b.Metrics = parseProcQueues(fields[4])
+		}
+		entries = append(entries, sockEntry{binding: b, inode: inode})
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
+	return entries, nil
+}
 
-	// Batch-map inodes to PIDs
-	inodes := make([]uint64, len(entries))
-	for i, e := range entries {
-		inodes[i] = e.inode
-	}
-	inodePIDMap := mapInodesToPIDs(inodes)
-
-	bindings := make([]domain.PortBinding, len(entries))
-	for i, e := range entries {
-		b := e.binding
-		if pid, ok := inodePIDMap[e.inode]; ok {
-			b.PID = pid
-		}
-		bindings[i] = b
+// newBinding builds a PortBinding, collapsing IPv4-mapped addresses on
+// dual-stack sockets so they are reported as IPv4.
+func newBinding(proto domain.Protocol, localIP net.IP, localPort uint16, remoteIP net.IP, remotePort uint16, state uint8) domain.PortBinding {
+	localIP = domain.NormalizeIP(localIP)
+	return domain.PortBinding{
+		Protocol: proto, Family: domain.FamilyOf(localIP),
+		LocalIP: localIP, LocalPort: localPort,
+		RemoteIP: domain.NormalizeIP(remoteIP), RemotePort: remotePort,
+		State: mapLinuxState(state),
 	}
-	return bindings, nil
 }
 
 func parseHexAddr(s string) (net.IP, uint16) {
@@ -232,27 +568,25 @@ func parseHexAddr(s string) (net.IP, uint16) {
 		return nil, 0
 	}
 	ipBytes, _ := hex.DecodeString(parts[0])
-	if len(ipBytes) == 4 {
-		ipBytes[0], ipBytes[3] = ipBytes[3], ipBytes[0]
-		ipBytes[1], ipBytes[2] = ipBytes[2], ipBytes[1]
+	if len(ipBytes) != net.IPv4len && len(ipBytes) != net.IPv6len {
+		return nil, 0
+	}
+	// The kernel prints addresses as host-order 32-bit words.
+	for i := 0; i+4 <= len(ipBytes); i += 4 {
+		ipBytes[i], ipBytes[i+3] = ipBytes[i+3], ipBytes[i]
+		ipBytes[i+1], ipBytes[i+2] = ipBytes[i+2], ipBytes[i+1]
 	}
 	port, _ := strconv.ParseUint(parts[1], 16, 16)
 	return net.IP(ipBytes), uint16(port)
 }
 
-func mapLinuxState(st uint8) domain.SocketState {
-	switch st {
-	case 0x0A:
-		return domain.StateListen
-	case 0x01:
-		return domain.StateEstablished
-	case 0x06:
-		return domain.StateTimeWait
-	case 0x08:
-		return domain.StateCloseWait
-	default:
-		return domain.StateUnknown
+func hasFamily(families []domain.AddressFamily, f domain.AddressFamily) bool {
+	for _, v := range families {
+		if v == f {
+			return true
+		}
 	}
+	return false
 }
 
 func hasProto(protos []domain.Protocol, p domain.Protocol) bool {
@@ -4,11 +4,21 @@ package linux
 
 import (
 	"context"
+	"encoding/binary"
+	"fmt"
 	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"syscall"
 	"testing"
 
+	"golang.org/x/sys/unix"
+
 	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
 func TestLinuxEnumerator_ListReturnsValidBindings(t *testing.T) {
@@ -70,3 +80,732 @@ func TestLinuxEnumerator_NetlinkAndProcFallback(t *testing.T) {
 		}
 	}
 }
+
+func TestLinuxEnumerator_IPv6Listener(t *testing.T) {
+	ln, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	defer ln.Close()
+
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	enum := NewEnumerator()
+	result, err := enum.List(context.Background(), &domain.Filter{
+		Ports:    []uint16{port},
+		Families: []domain.AddressFamily{domain.FamilyIPv6},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) == 0 {
+		t.Fatalf("expected IPv6 binding on port %d", port)
+	}
+
+	b := result.Data[0]
+	if b.Family != domain.FamilyIPv6 {
+		t.Errorf("expected ipv6, got %s", b.Family)
+	}
+	if !b.LocalIP.Equal(net.IPv6loopback) {
+		t.Errorf("expected ::1, got %s", b.LocalIP)
+	}
+	if b.LocalAddr() != net.JoinHostPort("::1", strconv.Itoa(int(port))) {
+		t.Errorf("unexpected local addr %s", b.LocalAddr())
+	}
+}
+
+func TestLinuxEnumerator_InterfaceFilter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	lo, err := net.InterfaceByIndex(1)
+	if err != nil || lo.Flags&net.FlagLoopback == 0 {
+		t.Skip("no loopback interface at index 1")
+	}
+
+	enum := NewEnumerator()
+	for iface, want := range map[string]int{lo.Name: 1, "no-such-iface0": 0} {
+		result, err := enum.List(context.Background(), &domain.Filter{
+			Protocols:  []domain.Protocol{domain.TCP},
+			Ports:      []uint16{port},
+			Interfaces: []string{iface},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Data) != want {
+			t.Fatalf("--iface %s: expected %d bindings, got %d", iface, want, len(result.Data))
+		}
+		if want == 1 && result.Data[0].Interface != lo.Name {
+			t.Errorf("expected interface %s, got %q", lo.Name, result.Data[0].Interface)
+		}
+	}
+}
+
+func TestParseHexAddr(t *testing.T) {
+	tests := []struct {
+		in   string
+		ip   string
+		port uint16
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080},
+		{"00000000000000000000000001000000:0050", "::1", 80},
+		{"0000000000000000FFFF00000100007F:0BB8", "127.0.0.1", 3000},
+	}
+	for _, tt := range tests {
+		ip, port := parseHexAddr(tt.in)
+		if !domain.NormalizeIP(ip).Equal(net.ParseIP(tt.ip)) || port != tt.port {
+			t.Errorf("parseHexAddr(%q) = %s:%d, want %s:%d", tt.in, ip, port, tt.ip, tt.port)
+		}
+	}
+}
+
+func TestLinuxEnumerator_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "porthog.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	enum := NewEnumerator()
+	result, err := enum.List(context.Background(), &domain.Filter{
+		Protocols: []domain.Protocol{domain.Unix},
+		Paths:     []string{path},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != 1 {
+		t.Fatalf("expected 1 binding for %s, got %d", path, len(result.Data))
+	}
+
+	b := result.Data[0]
+	if b.SockType != domain.SockStream {
+		t.Errorf("expected stream, got %s", b.SockType)
+	}
+	if b.State != domain.StateListen {
+		t.Errorf("expected LISTEN, got %s", b.State)
+	}
+	if b.PID != int32(os.Getpid()) {
+		t.Errorf("expected PID %d, got %d", os.Getpid(), b.PID)
+	}
+}
+
+func TestParseProcNetUnix(t *testing.T) {
+	fixture := `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 3729 /run/app.sock
+0000000000000000: 00000003 00000000 00000000 0001 03 18058
+0000000000000000: 00000002 00000000 00000000 0002 01 4410 @abstract
+0000000000000000: 00000002 00000000 00000000 0005 03 4411 /run/with space.sock
+`
+	path := filepath.Join(t.TempDir(), "unix")
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := parseProcNetUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 named sockets, got %d", len(entries))
+	}
+	want := []struct {
+		path  string
+		typ   domain.SocketType
+		state domain.SocketState
+		inode uint64
+	}{
+		{"/run/app.sock", domain.SockStream, domain.StateListen, 3729},
+		{"@abstract", domain.SockDgram, domain.StateClosed, 4410},
+		{"/run/with space.sock", domain.SockSeqpacket, domain.StateEstablished, 4411},
+	}
+	for i, w := range want {
+		b := entries[i].binding
+		if b.Path != w.path || b.SockType != w.typ || b.State != w.state || entries[i].inode != w.inode {
+			t.Errorf("entry %d = %q %s %s %d, want %q %s %s %d",
+				i, b.Path, b.SockType, b.State, entries[i].inode, w.path, w.typ, w.state, w.inode)
+		}
+	}
+}
+
+// TestUnixStateProcMatchesDiag feeds the same sockets to the /proc and the
+// unix_diag parsers; both backends must report identical bindings.
+func TestUnixStateProcMatchesDiag(t *testing.T) {
+	fixture := []struct {
+		path    string
+		typ     uint16
+		flags   uint32
+		st      uint8 // SS_* state in /proc/net/unix
+		skState uint8 // sk_state in unix_diag_msg
+		inode   uint32
+	}{
+		{"/run/app.sock", unix.SOCK_STREAM, procUnixAcceptCon, 1, tcpListen, 3729},
+		{"/run/db.sock", unix.SOCK_STREAM, 0, procUnixConnected, tcpEstablished, 3730},
+		{"@abstract", unix.SOCK_DGRAM, 0, 1, tcpClose, 4410},
+		{"/run/seq.sock", unix.SOCK_SEQPACKET, 0, 4, tcpClose, 4411},
+	}
+
+	var proc strings.Builder
+	proc.WriteString("Num       RefCount Protocol Flags    Type St Inode Path\n")
+	for _, f := range fixture {
+		fmt.Fprintf(&proc, "0000000000000000: 00000002 00000000 %08x %04x %02x %d %s\n",
+			f.flags, f.typ, f.st, f.inode, f.path)
+	}
+	path := filepath.Join(t.TempDir(), "unix")
+	if err := os.WriteFile(path, []byte(proc.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := parseProcNetUnix(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(fixture) {
+		t.Fatalf("expected %d sockets from /proc, got %d", len(fixture), len(entries))
+	}
+
+	for i, f := range fixture {
+		name := []byte(f.path)
+		if name[0] == '@' {
+			name[0] = 0
+		}
+		attr := make([]byte, (4+len(name)+3)&^3)
+		binary.LittleEndian.PutUint16(attr[0:2], uint16(4+len(name)))
+		binary.LittleEndian.PutUint16(attr[2:4], unixDiagName)
+		copy(attr[4:], name)
+		msg := make([]byte, 16, 16+len(attr))
+		msg[0], msg[1], msg[2] = unix.AF_UNIX, uint8(f.typ), f.skState
+		binary.LittleEndian.PutUint32(msg[4:8], f.inode)
+		msg = append(msg, attr...)
+
+		diag, inode, ok := parseUnixDiagMsg(msg)
+		if !ok {
+			t.Fatalf("%s: unix_diag message not parsed", f.path)
+		}
+		got := entries[i].binding
+		if got.Path != diag.Path || got.SockType != diag.SockType || got.State != diag.State || entries[i].inode != inode {
+			t.Errorf("%s: /proc gave %s %s (inode %d), unix_diag gave %s %s (inode %d)",
+				f.path, got.SockType, got.State, entries[i].inode, diag.SockType, diag.State, inode)
+		}
+	}
+}
+
+func TestLinuxEnumerator_RawSocket(t *testing.T) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_ICMP)
+	if err != nil {
+		t.Skipf("cannot open a raw socket: %v", err)
+	}
+	defer syscall.Close(fd)
+
+	enum := NewEnumerator()
+	result, err := enum.List(context.Background(), &domain.Filter{
+		Protocols: []domain.Protocol{domain.Raw},
+		Families:  []domain.AddressFamily{domain.FamilyIPv4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range result.Data {
+		if b.PID != int32(os.Getpid()) {
+			continue
+		}
+		if b.IPProtocol != syscall.IPPROTO_ICMP || b.LocalPort != 0 {
+			t.Errorf("expected IP protocol 1 and no port, got %d and %d", b.IPProtocol, b.LocalPort)
+		}
+		if got := b.LocalAddr(); got != "0.0.0.0:icmp" {
+			t.Errorf("expected 0.0.0.0:icmp, got %s", got)
+		}
+		return
+	}
+	t.Fatal("raw socket not found")
+}
+
+func TestParseProcNetRaw(t *testing.T) {
+	fixture := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  1: 00000000:0001 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 46343 2 0000000000000000 0
+ 17: 0100007F:002F 00000000:0000 07 00000000:00000000 00:00000000 00000000  1000        0 46345 2 0000000000000000 0
+`
+	path := filepath.Join(t.TempDir(), "raw")
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := inetSources[4]
+	if src.proto != domain.Raw || src.family != domain.FamilyIPv4 {
+		t.Fatalf("unexpected source %s", src.label)
+	}
+	entries, err := parseProcNet(src, path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setIPProtocol(src, entries)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 raw sockets, got %d", len(entries))
+	}
+	want := []string{"0.0.0.0:icmp", "127.0.0.1:gre"}
+	for i, w := range want {
+		b := entries[i].binding
+		if got := b.LocalAddr(); got != w || b.LocalPort != 0 {
+			t.Errorf("entry %d = %s (port %d), want %s", i, got, b.LocalPort, w)
+		}
+	}
+	if entries[1].binding.UID != 1000 || entries[1].inode != 46345 {
+		t.Errorf("unexpected uid/inode: %d/%d", entries[1].binding.UID, entries[1].inode)
+	}
+}
+
+func TestMapLinuxState_AllKernelStates(t *testing.T) {
+	for st := uint8(1); st <= 13; st++ {
+		if got := mapLinuxState(st); got == domain.StateUnknown {
+			t.Errorf("kernel state %d mapped to UNKNOWN", st)
+		}
+	}
+	if got := mapLinuxState(0x07); got != domain.StateClosed {
+		t.Errorf("expected TCP_CLOSE to map to CLOSED, got %s", got)
+	}
+	for _, name := range []string{"syn-sent", "FIN_WAIT1", "last_ack", "close"} {
+		if _, err := domain.ParseSocketState(name); err != nil {
+			t.Errorf("ParseSocketState(%q): %v", name, err)
+		}
+	}
+}
+
+func TestEnumNetlink_KernelPortFilter(t *testing.T) {
+	var ports []uint16
+	for i := 0; i < 3; i++ {
+		ln, err := net.Listen("tcp4", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ln.Close()
+		ports = append(ports, uint16(ln.Addr().(*net.TCPAddr).Port))
+	}
+
+	filter := &domain.Filter{
+		Ports:  []uint16{ports[0], ports[2]},
+		States: []domain.SocketState{domain.StateListen},
+	}
+	ranges, ok := portRanges(filter)
+	if !ok {
+		t.Fatal("expected port ranges to be satisfiable")
+	}
+	entries, err := NewEnumerator().enumNetlink(context.Background(), inetSources[0], filter, ranges)
+	if err != nil {
+		t.Skipf("sock_diag unavailable: %v", err)
+	}
+
+	got := map[uint16]bool{}
+	for _, e := range entries {
+		if e.binding.State != domain.StateListen {
+			t.Errorf("kernel returned non-LISTEN socket on port %d: %s", e.binding.LocalPort, e.binding.State)
+		}
+		got[e.binding.LocalPort] = true
+	}
+	if len(got) != 2 || !got[ports[0]] || !got[ports[2]] {
+		t.Errorf("expected only ports %d and %d, got %v", ports[0], ports[2], got)
+	}
+}
+
+// BenchmarkPortFilter looks up one port among many listeners twice: with the
+// port compiled into INET_DIAG_REQ_BYTECODE, and with the kernel dumping
+// every socket and only prefilter applying the port in Go.
+func BenchmarkPortFilter(b *testing.B) {
+	var listeners []net.Listener
+	for range 2000 {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			break // fd limit reached
+		}
+		listeners = append(listeners, ln)
+	}
+	defer func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+	}()
+	if len(listeners) == 0 {
+		b.Skip("could not open any listeners")
+	}
+	port := uint16(listeners[len(listeners)/2].Addr().(*net.TCPAddr).Port)
+	filter := &domain.Filter{Ports: []uint16{port}, Protocols: []domain.Protocol{domain.TCP}}
+	ranges, _ := portRanges(filter)
+	e := NewEnumerator()
+
+	for _, bc := range []struct {
+		name   string
+		ranges []domain.PortRange
+	}{
+		{"bytecode", ranges},
+		{"prefilter", nil},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			var dumped, kept int
+			b.ReportAllocs()
+			for b.Loop() {
+				dumped, kept = 0, 0
+				for _, src := range inetSources {
+					if src.procOnly || !wantSource(filter, src) {
+						continue
+					}
+					se, err := e.enumNetlink(context.Background(), src, filter, bc.ranges)
+					if err != nil {
+						b.Skipf("sock_diag unavailable: %v", err)
+					}
+					dumped += len(se)
+					kept += len(prefilter(se, filter))
+				}
+			}
+			if kept == 0 {
+				b.Fatalf("port %d not found", port)
+			}
+			b.ReportMetric(float64(dumped), "sockets/op")
+		})
+	}
+}
+
+func TestLinuxEnumerator_Metrics(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	conn, err := net.Dial("tcp4", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+
+	enum := NewEnumeratorWithOptions(ports.EnumeratorOptions{Metrics: true})
+	result, err := enum.List(context.Background(), &domain.Filter{
+		Ports:  []uint16{port},
+		States: []domain.SocketState{domain.StateListen},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) == 0 {
+		t.Fatalf("expected listener on port %d", port)
+	}
+	m := result.Data[0].Metrics
+	if m == nil {
+		t.Fatal("expected metrics to be populated")
+	}
+	// The accepted-but-unclaimed connection sits in the listen backlog.
+	if m.RecvQueue != 1 {
+		t.Errorf("expected accept queue depth 1, got %d", m.RecvQueue)
+	}
+	if m.HasTCPInfo && m.Cwnd == 0 {
+		t.Error("expected non-zero cwnd from tcp_info")
+	}
+}
+
+func TestLinuxEnumerator_SharedSocketOwners(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// The child inherits the listening socket as fd 3, like a pre-forked worker.
+	child := exec.Command("sleep", "10")
+	child.ExtraFiles = []*os.File{f}
+	if err := child.Start(); err != nil {
+		t.Skipf("cannot start child process: %v", err)
+	}
+	defer func() {
+		child.Process.Kill()
+		child.Wait()
+	}()
+
+	enum := NewEnumerator()
+	result, err := enum.List(context.Background(), &domain.Filter{
+		Ports:  []uint16{port},
+		States: []domain.SocketState{domain.StateListen},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != 1 {
+		t.Fatalf("expected 1 listener, got %d", len(result.Data))
+	}
+
+	pids := result.Data[0].OwnerPIDs()
+	want := map[int32]bool{int32(os.Getpid()): false, int32(child.Process.Pid): false}
+	for _, p := range pids {
+		if _, ok := want[p]; ok {
+			want[p] = true
+		}
+	}
+	for p, seen := range want {
+		if !seen {
+			t.Errorf("expected PID %d among owners %v", p, pids)
+		}
+	}
+	for _, o := range result.Data[0].Owners {
+		if o.PID == int32(child.Process.Pid) && (len(o.FDs) != 1 || o.FDs[0] != 3) {
+			t.Errorf("expected child to hold fd 3, got %v", o.FDs)
+		}
+	}
+}
+
+func TestLinuxEnumerator_OtherNetworkNamespace(t *testing.T) {
+	// The child gets a fresh network namespace; a listener is then opened
+	// inside it from a thread that joined the namespace.
+	child := exec.Command("sleep", "10")
+	child.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNET}
+	if err := child.Start(); err != nil {
+		t.Skipf("cannot create network namespace: %v", err)
+	}
+	defer func() {
+		child.Process.Kill()
+		child.Wait()
+	}()
+	childPID := strconv.Itoa(child.Process.Pid)
+	nsPath := filepath.Join("/proc", childPID, "ns", "net")
+
+	var ln net.Listener
+	var listenErr error
+	if err := inNamespace(nsPath, func() { ln, listenErr = net.Listen("tcp4", ":0") }); err != nil {
+		t.Skipf("cannot enter network namespace: %v", err)
+	}
+	if listenErr != nil {
+		t.Fatal(listenErr)
+	}
+	defer ln.Close()
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	nsIno, err := netnsInode(nsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter := &domain.Filter{Ports: []uint16{port}, Protocols: []domain.Protocol{domain.TCP}}
+
+	for name, opts := range map[string]ports.EnumeratorOptions{
+		"netns":          {Netns: childPID},
+		"all-namespaces": {AllNamespaces: true},
+	} {
+		result, err := NewEnumeratorWithOptions(opts).List(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var found bool
+		for _, b := range result.Data {
+			if b.Netns != nil && b.Netns.Inode == nsIno {
+				found = true
+				if b.PID != int32(os.Getpid()) {
+					t.Errorf("%s: expected owner %d, got %d", name, os.Getpid(), b.PID)
+				}
+				if b.Netns.PID != int32(child.Process.Pid) || b.Netns.Process != "sleep" {
+					t.Errorf("%s: expected representative sleep/%d, got %s/%d", name, child.Process.Pid, b.Netns.Process, b.Netns.PID)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s: listener on port %d not reported in namespace %d", name, port, nsIno)
+		}
+	}
+
+	// The default enumeration stays in the caller's namespace.
+	result, err := NewEnumerator().List(context.Background(), filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range result.Data {
+		if b.PID == int32(os.Getpid()) {
+			t.Errorf("listener from another namespace leaked into default listing: %+v", b)
+		}
+	}
+}
+
+func TestDiscoverNamespaces_IncludesOwn(t *testing.T) {
+	self, err := netnsInode("/proc/self/ns/net")
+	if err != nil {
+		t.Skipf("cannot read own network namespace: %v", err)
+	}
+	targets, err := discoverNamespaces(defaultProcRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) == 0 || !targets[0].host || targets[0].ns.Inode != self {
+		t.Fatalf("expected own namespace %d first, got %+v", self, targets)
+	}
+
+	own, err := resolveNamespace(defaultProcRoot, strconv.Itoa(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !own.host || own.ns.Inode != self {
+		t.Errorf("expected PID %d to resolve to own namespace, got %+v", os.Getpid(), own)
+	}
+	if _, err := resolveNamespace(defaultProcRoot, "porthog-no-such-netns"); err == nil {
+		t.Error("expected error for unknown namespace name")
+	}
+}
+
+func TestLinuxEnumerator_TimeWaitHasNoOwner(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	client, err := net.Dial("tcp4", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The side that closes first ends up in TIME_WAIT.
+	server.Close()
+	client.Close()
+
+	result, err := NewEnumerator().List(context.Background(), &domain.Filter{
+		Protocols: []domain.Protocol{domain.TCP},
+		States:    []domain.SocketState{domain.StateTimeWait, domain.StateListen},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sawListener, sawTimeWait bool
+	for _, b := range result.Data {
+		if b.LocalPort != port {
+			continue
+		}
+		switch b.State {
+		case domain.StateListen:
+			sawListener = true
+			if b.OwnerKind != domain.OwnerProcess || !b.HasUID || int(b.UID) != os.Geteuid() {
+				t.Errorf("listener: expected process owner with uid %d, got %v uid=%d(%v)", os.Geteuid(), b.OwnerKind, b.UID, b.HasUID)
+			}
+		case domain.StateTimeWait:
+			sawTimeWait = true
+			if b.OwnerKind != domain.OwnerNone || b.PID != 0 {
+				t.Errorf("TIME_WAIT: expected no owner, got %v PID %d", b.OwnerKind, b.PID)
+			}
+		}
+	}
+	if !sawListener {
+		t.Error("listener not reported")
+	}
+	if !sawTimeWait {
+		t.Log("TIME_WAIT socket not observed; it may have been reused")
+	}
+}
+
+func TestClassifyUnowned(t *testing.T) {
+	own := sockEntry{binding: domain.PortBinding{UID: uint32(os.Geteuid()), HasUID: true}, inode: 42}
+	other := sockEntry{binding: domain.PortBinding{UID: uint32(os.Geteuid()) + 1, HasUID: true}, inode: 43}
+	released := sockEntry{inode: 0}
+
+	tests := []struct {
+		name       string
+		e          sockEntry
+		restricted bool
+		want       domain.OwnerKind
+	}{
+		{"released", released, true, domain.OwnerNone},
+		{"other user, restricted", other, true, domain.OwnerHidden},
+		{"other user, full view", other, false, domain.OwnerKernel},
+		{"own socket, restricted", own, true, domain.OwnerKernel},
+	}
+	for _, tt := range tests {
+		if got := classifyUnowned(tt.e, tt.restricted, os.Geteuid()); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseProcSCTP(t *testing.T) {
+	dir := t.TempDir()
+	eps := ` ENDPT     SOCK   STY SST HBKT LPORT   UID INODE LADDRS
+ffff8f4a7d2e3000 ffff8f4a41c2a000 2   10  29   2905      0 81234 10.0.0.1 10.0.1.1
+ffff8f4a7d2e3800 ffff8f4a41c2b000 1   10  11   36412  1000 81240 ::1
+`
+	assocs := ` ASSOC     SOCK   STY SST ST HBKT ASSOC-ID TX_QUEUE RX_QUEUE UID INODE LPORT RPORT LADDRS <-> RADDRS HBINT INS OUTS MAXRT T1X T2X RTXC wmema wmemq sndbuf rcvbuf
+ffff8f4a7c1c0000 ffff8f4a41c2a000 2   1   3  1234    5        0        0     0 81234  2905 38412 10.0.1.1 *10.0.0.1 <-> *10.0.0.9 10.0.1.9	    30000    10    10   10    0    0        0        1        0   212992   212992
+`
+	if err := os.WriteFile(filepath.Join(dir, "eps"), []byte(eps), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assocs"), []byte(assocs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := parseProcSCTP(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 2 endpoints and 1 association, got %d", len(entries))
+	}
+
+	ep := entries[0].binding
+	if ep.Protocol != domain.SCTP || ep.State != domain.StateListen || ep.LocalPort != 2905 || entries[0].inode != 81234 {
+		t.Errorf("unexpected endpoint: %+v inode %d", ep, entries[0].inode)
+	}
+	if len(ep.LocalIPs) != 2 || !ep.LocalIPs[1].Equal(net.ParseIP("10.0.1.1")) {
+		t.Errorf("expected both multi-homed addresses, got %v", ep.LocalIPs)
+	}
+	if entries[1].binding.Family != domain.FamilyIPv6 || entries[1].binding.UID != 1000 {
+		t.Errorf("unexpected IPv6 endpoint: %+v", entries[1].binding)
+	}
+
+	as := entries[2].binding
+	if as.State != domain.StateEstablished || as.RemotePort != 38412 {
+		t.Errorf("unexpected association: %+v", as)
+	}
+	if !as.LocalIP.Equal(net.ParseIP("10.0.0.1")) || !as.RemoteIP.Equal(net.ParseIP("10.0.0.9")) {
+		t.Errorf("expected primary addresses 10.0.0.1 <-> 10.0.0.9, got %s <-> %s", as.LocalIP, as.RemoteIP)
+	}
+}
+
+func TestParseSCTPDiagMsg(t *testing.T) {
+	// An association on 10.0.0.1:2905 <-> 10.0.0.9:38412, multi-homed
+	// locally, in SCTP_STATE_ESTABLISHED (3).
+	msg := make([]byte, inetDiagMsgLen)
+	msg[0] = 2 // AF_INET
+	msg[1] = 3
+	binary.BigEndian.PutUint16(msg[4:6], 2905)
+	binary.BigEndian.PutUint16(msg[6:8], 38412)
+	copy(msg[8:12], net.ParseIP("10.0.0.1").To4())
+	copy(msg[24:28], net.ParseIP("10.0.0.9").To4())
+	binary.LittleEndian.PutUint32(msg[68:72], 81234)
+
+	locals := make([]byte, 4+2*sockaddrStorageLen)
+	binary.LittleEndian.PutUint16(locals[0:2], uint16(len(locals)))
+	binary.LittleEndian.PutUint16(locals[2:4], inetDiagLocals)
+	for i, ip := range []string{"10.0.0.1", "10.0.1.1"} {
+		sa := locals[4+i*sockaddrStorageLen:]
+		binary.LittleEndian.PutUint16(sa[0:2], 2)
+		copy(sa[4:8], net.ParseIP(ip).To4())
+	}
+	msg = append(msg, locals...)
+
+	b, ino := parseSCTPDiagMsg(msg)
+	if ino != 81234 || b.Protocol != domain.SCTP || b.State != domain.StateEstablished {
+		t.Errorf("unexpected association: %+v inode %d", b, ino)
+	}
+	if len(b.LocalIPs) != 2 || !b.LocalIPs[1].Equal(net.ParseIP("10.0.1.1")) {
+		t.Errorf("expected 2 local addresses, got %v", b.LocalIPs)
+	}
+}
@@ -0,0 +1,308 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+const (
+	procTCPHeader  = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+	procUnixHeader = "Num       RefCount Protocol Flags    Type St Inode Path\n"
+)
+
+// procFixture is a synthetic procfs tree for driving the enumerator
+// without touching the live system.
+type procFixture struct {
+	t    *testing.T
+	root string
+}
+
+func newProcFixture(t *testing.T) *procFixture {
+	t.Helper()
+	f := &procFixture{t: t, root: filepath.Join(t.TempDir(), "proc")}
+	for _, table := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		f.write("net/"+table, procTCPHeader)
+	}
+	f.write("net/unix", procUnixHeader)
+	return f
+}
+
+func (f *procFixture) write(rel, content string) {
+	f.t.Helper()
+	path := filepath.Join(f.root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// process creates <root>/<pid> holding the given socket inodes as fds 3, 4, ...
+func (f *procFixture) process(pid int32, comm string, inodes ...uint64) {
+	f.t.Helper()
+	dir := filepath.Join(f.root, strconv.Itoa(int(pid)))
+	f.write(filepath.Join(strconv.Itoa(int(pid)), "comm"), comm+"\n")
+	fdDir := filepath.Join(dir, "fd")
+	if err := os.RemoveAll(fdDir); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.MkdirAll(fdDir, 0o755); err != nil {
+		f.t.Fatal(err)
+	}
+	// Descriptors that are not sockets must be skipped.
+	if err := os.Symlink("/dev/null", filepath.Join(fdDir, "0")); err != nil {
+		f.t.Fatal(err)
+	}
+	for i, ino := range inodes {
+		link := "socket:[" + strconv.FormatUint(ino, 10) + "]"
+		if err := os.Symlink(link, filepath.Join(fdDir, strconv.Itoa(3+i))); err != nil {
+			f.t.Fatal(err)
+		}
+	}
+}
+
+func (f *procFixture) exit(pid int32) {
+	f.t.Helper()
+	if err := os.RemoveAll(filepath.Join(f.root, strconv.Itoa(int(pid)))); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *procFixture) list(e *Enumerator, filter *domain.Filter) *domain.PartialResult[[]domain.PortBinding] {
+	f.t.Helper()
+	result, err := e.List(context.Background(), filter)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return result
+}
+
+func (f *procFixture) enumerator(opts ports.EnumeratorOptions) *Enumerator {
+	opts.ProcRoot = f.root
+	return NewEnumeratorWithOptions(opts)
+}
+
+// tcpLine formats a /proc/net/tcp row; addresses are in the kernel's hex
+// notation.
+func tcpLine(sl int, local, remote string, state uint8, uid uint32, inode uint64) string {
+	return "   " + strconv.Itoa(sl) + ": " + local + " " + remote + " " +
+		strings.ToUpper(strconv.FormatUint(uint64(state), 16)) +
+		" 00000000:00000000 00:00000000 00000000 " + strconv.Itoa(int(uid)) +
+		"        0 " + strconv.FormatUint(inode, 10) + " 1 0000000000000000 100 0 0 10 0\n"
+}
+
+func byPort(bindings []domain.PortBinding) map[uint16]domain.PortBinding {
+	m := make(map[uint16]domain.PortBinding, len(bindings))
+	for _, b := range bindings {
+		m[b.LocalPort] = b
+	}
+	return m
+}
+
+func TestFixture_ProcessKernelAndReleasedSockets(t *testing.T) {
+	f := newProcFixture(t)
+	f.write("net/tcp", procTCPHeader+
+		tcpLine(0, "0100007F:1F90", "00000000:0000", 0x0A, 1000, 100)+ // 127.0.0.1:8080, nginx
+		tcpLine(1, "00000000:0801", "00000000:0000", 0x0A, 0, 200)+ // 0.0.0.0:2049, nfsd
+		tcpLine(2, "0100007F:C350", "0100007F:1F90", 0x06, 0, 0)) // TIME_WAIT
+	f.process(42, "nginx", 100)
+	f.process(43, "bash")
+
+	got := byPort(f.list(f.enumerator(ports.EnumeratorOptions{}), nil).Data)
+	if len(got) != 3 {
+		t.Fatalf("got %d bindings, want 3: %v", len(got), got)
+	}
+	if b := got[8080]; b.OwnerKind != domain.OwnerProcess || b.PID != 42 || b.UID != 1000 ||
+		len(b.Owners) != 1 || !slices.Equal(b.Owners[0].FDs, []int32{3}) {
+		t.Errorf("8080: got %+v, want held by PID 42 on fd 3", b)
+	}
+	if b := got[2049]; b.OwnerKind != domain.OwnerKernel || b.PID != 0 {
+		t.Errorf("2049: owner %s PID %d, want kernel", b.OwnerKind, b.PID)
+	}
+	if b := got[50000]; b.OwnerKind != domain.OwnerNone || b.State != domain.StateTimeWait {
+		t.Errorf("50000: owner %s state %s, want released TIME_WAIT", b.OwnerKind, b.State)
+	}
+}
+
+func TestFixture_IPv6(t *testing.T) {
+	f := newProcFixture(t)
+	f.write("net/tcp6", procTCPHeader+
+		tcpLine(0, "00000000000000000000000001000000:1F90", "00000000000000000000000000000000:0000", 0x0A, 0, 100)+ // [::1]:8080
+		tcpLine(1, "0000000000000000FFFF00000100007F:0050", "00000000000000000000000000000000:0000", 0x0A, 0, 101)+ // ::ffff:127.0.0.1:80
+		tcpLine(2, "000080FE00000000FF005450120000FE:01BB", "00000000000000000000000000000000:0000", 0x0A, 0, 102)) // [fe80::5054:ff:fe00:12]:443
+	// No udp6: IPv6 disabled for UDP is not an error.
+	if err := os.Remove(filepath.Join(f.root, "net/udp6")); err != nil {
+		t.Fatal(err)
+	}
+	f.process(7, "api", 100, 101, 102)
+
+	result := f.list(f.enumerator(ports.EnumeratorOptions{}), nil)
+	if len(result.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", result.Warnings)
+	}
+	got := byPort(result.Data)
+	tests := []struct {
+		port   uint16
+		ip     string
+		family domain.AddressFamily
+	}{
+		{8080, "::1", domain.FamilyIPv6},
+		{80, "127.0.0.1", domain.FamilyIPv4}, // IPv4-mapped, reported as IPv4
+		{443, "fe80::5054:ff:fe00:12", domain.FamilyIPv6},
+	}
+	for _, tt := range tests {
+		b, ok := got[tt.port]
+		if !ok {
+			t.Errorf("port %d missing", tt.port)
+			continue
+		}
+		if b.LocalIP.String() != tt.ip || b.Family != tt.family || b.PID != 7 {
+			t.Errorf("port %d: got %s %s PID %d, want %s %s PID 7", tt.port, b.LocalIP, b.Family, b.PID, tt.ip, tt.family)
+		}
+	}
+}
+
+func TestFixture_FamilyFilterSeesIPv4MappedSockets(t *testing.T) {
+	f := newProcFixture(t)
+	f.write("net/tcp", procTCPHeader+
+		tcpLine(0, "0100007F:1F90", "00000000:0000", 0x0A, 0, 100)) // 127.0.0.1:8080
+	f.write("net/tcp6", procTCPHeader+
+		tcpLine(0, "0000000000000000FFFF00000100007F:0050", "00000000000000000000000000000000:0000", 0x0A, 0, 101)+ // ::ffff:127.0.0.1:80
+		tcpLine(1, "00000000000000000000000001000000:01BB", "00000000000000000000000000000000:0000", 0x0A, 0, 102)) // [::1]:443
+	f.process(7, "api", 100, 101, 102)
+	e := f.enumerator(ports.EnumeratorOptions{})
+
+	tests := []struct {
+		family domain.AddressFamily
+		want   []uint16
+	}{
+		{domain.FamilyIPv4, []uint16{80, 8080}},
+		{domain.FamilyIPv6, []uint16{443}},
+	}
+	for _, tt := range tests {
+		var got []uint16
+		for _, b := range f.list(e, &domain.Filter{Families: []domain.AddressFamily{tt.family}}).Data {
+			got = append(got, b.LocalPort)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got ports %v, want %v", tt.family, got, tt.want)
+		}
+	}
+}
+
+func TestFixture_MalformedLines(t *testing.T) {
+	f := newProcFixture(t)
+	f.write("net/tcp", procTCPHeader+
+		"garbage\n"+
+		"   0: 0100007F:1F90 00000000:0000 0A\n"+ // torn mid-line
+		tcpLine(1, "ZZZZ007F:1F90", "00000000:0000", 0x0A, 0, 100)+
+		tcpLine(2, "0100007F1F90", "00000000:0000", 0x0A, 0, 101)+
+		strings.Replace(tcpLine(3, "0100007F:1F91", "00000000:0000", 0x0A, 0, 102), " 102 ", " 10x2 ", 1)+
+		"\n"+
+		tcpLine(4, "0100007F:1F92", "00000000:0000", 0x0A, 0, 103)) // the one good row
+	f.write("net/unix", procUnixHeader+
+		"0000000000000000: 00000002 00000000 00010000 0001 01 bogus /run/bad.sock\n"+
+		"0000000000000000: 00000002 00000000 00010000 0001 01 500 /run/app.sock\n")
+	f.process(9, "app", 103, 500)
+
+	result := f.list(f.enumerator(ports.EnumeratorOptions{}), nil)
+	if len(result.Data) != 1 || result.Data[0].LocalPort != 8082 || result.Data[0].PID != 9 {
+		t.Errorf("got %+v, want only 127.0.0.1:8082 held by PID 9", result.Data)
+	}
+
+	result = f.list(f.enumerator(ports.EnumeratorOptions{}), &domain.Filter{Protocols: []domain.Protocol{domain.Unix}})
+	if len(result.Data) != 1 || result.Data[0].Path != "/run/app.sock" || result.Data[0].PID != 9 {
+		t.Errorf("got %+v, want only /run/app.sock held by PID 9", result.Data)
+	}
+}
+
+func TestFixture_HidePID(t *testing.T) {
+	f := newProcFixture(t)
+	f.write("net/tcp", procTCPHeader+
+		tcpLine(0, "00000000:0016", "00000000:0000", 0x0A, 0, 300)+ // root's sshd, hidden
+		tcpLine(1, "0100007F:0BB8", "00000000:0000", 0x0A, 1000, 301)) // ours, yet unowned
+	f.write("self/mountinfo", "22 1 0:21 / "+f.root+" rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw,hidepid=invisible\n")
+
+	e := f.enumerator(ports.EnumeratorOptions{})
+	e.euid = 1000
+	result := f.list(e, nil)
+	got := byPort(result.Data)
+	if got[22].OwnerKind != domain.OwnerHidden {
+		t.Errorf("22: owner %s, want hidden", got[22].OwnerKind)
+	}
+	if got[3000].OwnerKind != domain.OwnerKernel {
+		t.Errorf("3000: owner %s, want kernel: our own sockets are never hidden from us", got[3000].OwnerKind)
+	}
+	if !result.Partial || result.DeniedCount != 1 || len(result.Warnings) != 1 {
+		t.Errorf("partial %v denied %d warnings %v, want one hidden socket reported", result.Partial, result.DeniedCount, result.Warnings)
+	}
+
+	// Without hidepid an unowned socket belongs to the kernel.
+	f.write("self/mountinfo", "22 1 0:21 / "+f.root+" rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw\n")
+	if got := byPort(f.list(e, nil).Data); got[22].OwnerKind != domain.OwnerKernel {
+		t.Errorf("22 without hidepid: owner %s, want kernel", got[22].OwnerKind)
+	}
+}
+
+func TestFixture_ReusedInode(t *testing.T) {
+	f := newProcFixture(t)
+	f.write("net/tcp", procTCPHeader+tcpLine(0, "0100007F:1F90", "00000000:0000", 0x0A, 0, 100))
+	f.process(10, "old", 100)
+	e := f.enumerator(ports.EnumeratorOptions{Incremental: true})
+	if got := byPort(f.list(e, nil).Data); got[8080].PID != 10 {
+		t.Fatalf("8080: PID %d, want 10", got[8080].PID)
+	}
+
+	// PID 10 swaps its socket for a new one on the same fd, so its fd count
+	// is unchanged, and inode 100 is reused for PID 20's new listener.
+	f.write("net/tcp", procTCPHeader+
+		tcpLine(0, "0100007F:1B58", "00000000:0000", 0x0A, 0, 100)+ // 7000
+		tcpLine(1, "0100007F:2382", "00000000:0000", 0x0A, 0, 101)) // 9090
+	f.process(10, "old", 101)
+	f.process(20, "new", 100)
+	got := byPort(f.list(e, nil).Data)
+	b7000, b9090 := got[7000], got[9090]
+	if owners := b7000.OwnerPIDs(); !slices.Equal(owners, []int32{20}) {
+		t.Errorf("7000 (reused inode): owners %v, want [20]", owners)
+	}
+	if owners := b9090.OwnerPIDs(); !slices.Equal(owners, []int32{10}) {
+		t.Errorf("9090: owners %v, want [10]", owners)
+	}
+
+	// An inode left behind by an exited process is not attributed to it.
+	f.exit(20)
+	f.process(30, "newer", 100)
+	b7000 = byPort(f.list(e, nil).Data)[7000]
+	if owners := b7000.OwnerPIDs(); !slices.Equal(owners, []int32{30}) {
+		t.Errorf("7000 after PID 20 exited: owners %v, want [30]", owners)
+	}
+}
+
+func TestFixture_ForeignRootListsPID1Namespace(t *testing.T) {
+	f := newProcFixture(t)
+	// <root>/net follows the reading process; <root>/1/net is the host's.
+	f.write("net/tcp", procTCPHeader+tcpLine(0, "0100007F:0457", "00000000:0000", 0x0A, 0, 100))
+	for _, table := range []string{"tcp6", "udp", "udp6"} {
+		f.write("1/net/"+table, procTCPHeader)
+	}
+	f.write("1/net/tcp", procTCPHeader+tcpLine(0, "0100007F:08AE", "00000000:0000", 0x0A, 0, 200))
+
+	for _, backend := range []ports.Backend{ports.BackendAuto, ports.BackendProc} {
+		result := f.list(f.enumerator(ports.EnumeratorOptions{Backend: backend}), nil)
+		if len(result.Data) != 1 || result.Data[0].LocalPort != 2222 {
+			t.Errorf("backend %s: got %+v, want only the host's port 2222", backend, result.Data)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package linux
+
+import (
+	"net"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// ifaceNames resolves interface indexes and local addresses to interface
+// names in the network namespace the calling thread is in.
+type ifaceNames struct {
+	byIndex map[uint32]string
+	byAddr  map[string]string
+}
+
+func loadIfaceNames() ifaceNames {
+	n := ifaceNames{byIndex: make(map[uint32]string), byAddr: make(map[string]string)}
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return n
+	}
+	for _, ifi := range ifaces {
+		n.byIndex[uint32(ifi.Index)] = ifi.Name
+		addrs, err := ifi.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if ipn, ok := a.(*net.IPNet); ok {
+				key := domain.NormalizeIP(ipn.IP).String()
+				if _, dup := n.byAddr[key]; !dup {
+					n.byAddr[key] = ifi.Name
+				}
+			}
+		}
+	}
+	return n
+}
+
+// labelInterfaces names the interface of each IP socket: the device it is
+// bound to (or, for IPv6 link-local addresses, scoped to), else the one
+// holding its local address. Wildcard sockets are left unlabelled.
+func labelInterfaces(entries []sockEntry) {
+	var names ifaceNames
+	for i := range entries {
+		b := &entries[i].binding
+		if b.LocalIP == nil || (b.IfIndex == 0 && b.LocalIP.IsUnspecified()) {
+			continue
+		}
+		if names.byIndex == nil {
+			names = loadIfaceNames()
+		}
+		if b.IfIndex != 0 {
+			b.Interface = names.byIndex[b.IfIndex]
+		} else {
+			b.Interface = names.byAddr[b.LocalIP.String()]
+		}
+	}
+}
@@ -3,107 +3,309 @@
 package linux
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
 )
 
-// mapInodeToPID scans /proc/<pid>/fd to find which PID owns a given socket inode.
-func mapInodeToPID(inode uint64) (int32, error) {
-	target := fmt.Sprintf("socket:[%d]", inode)
+const (
+	direntBufLen = 32 << 10
+	maxScanners  = 16
+)
 
-	entries, err := os.ReadDir("/proc")
-	if err != nil {
-		return 0, err
+// fdScanner maps socket inodes to the processes holding them by walking
+// /proc/<pid>/fd. Directories are read with getdents64 and links with
+// readlinkat relative to the directory fd, into buffers owned by each
+// worker, so the per-descriptor cost is two syscalls and no allocation.
+type fdScanner struct {
+	procRoot string
+	workers  int
+
+	// pids restricts the scan to these processes; nil scans all of them.
+	pids []int32
+	// firstOwner stops the scan once every inode has an owner, for callers
+	// that do not need every process sharing a socket.
+	firstOwner bool
+}
+
+func newFDScanner() *fdScanner {
+	return &fdScanner{procRoot: defaultProcRoot, workers: min(runtime.GOMAXPROCS(0), maxScanners)}
+}
+
+// scanState is shared by the workers of one scan.
+type scanState struct {
+	inodes    map[uint64]struct{}
+	mu        sync.Mutex
+	result    map[uint64][]domain.SocketOwner
+	remaining atomic.Int64 // inodes without an owner yet, for firstOwner
+	stop      atomic.Bool
+	denied    []int32 // guarded by mu
+}
+
+// scan returns the owners of each inode, sorted by PID, and the processes
+// whose descriptors could not be read.
+func (s *fdScanner) scan(inodes []uint64) (map[uint64][]domain.SocketOwner, []int32) {
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+	st := &scanState{
+		inodes: make(map[uint64]struct{}, len(inodes)),
+		result: make(map[uint64][]domain.SocketOwner, len(inodes)),
+	}
+	for _, ino := range inodes {
+		st.inodes[ino] = struct{}{}
 	}
+	st.remaining.Store(int64(len(st.inodes)))
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
+	pids := s.pids
+	if pids == nil {
+		var err error
+		if pids, err = listPIDs(s.procRoot); err != nil {
+			return st.result, nil
 		}
-		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
-		if err != nil {
-			continue
+	}
+
+	work := make(chan int32, s.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < max(s.workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := newFDWorker(s.procRoot)
+			for pid := range work {
+				if !st.stop.Load() {
+					w.scanPID(pid, st, s.firstOwner)
+				}
+			}
+		}()
+	}
+	for _, pid := range pids {
+		if st.stop.Load() {
+			break
 		}
+		work <- pid
+	}
+	close(work)
+	wg.Wait()
 
-		fdDir := filepath.Join("/proc", entry.Name(), "fd")
-		fds, err := os.ReadDir(fdDir)
-		if err != nil {
-			// hidepid or permission denied — skip silently
-			continue
+	for _, owners := range st.result {
+		sort.Slice(owners, func(i, j int) bool { return owners[i].PID < owners[j].PID })
+	}
+	return st.result, st.denied
+}
+
+// fdWorker holds the buffers one scanning goroutine reuses across PIDs.
+type fdWorker struct {
+	path   []byte
+	dirent []byte
+	link   []byte
+	held   map[uint64][]int32
+}
+
+func newFDWorker(procRoot string) *fdWorker {
+	return &fdWorker{
+		path:   append(make([]byte, 0, len(procRoot)+32), procRoot...),
+		dirent: make([]byte, direntBufLen),
+		link:   make([]byte, 64),
+		held:   make(map[uint64][]int32),
+	}
+}
+
+func (w *fdWorker) scanPID(pid int32, st *scanState, firstOwner bool) {
+	prefix := len(w.path)
+	w.path = append(w.path, '/')
+	w.path = strconv.AppendInt(w.path, int64(pid), 10)
+	w.path = append(w.path, "/fd"...)
+	dirfd, err := unix.Open(string(w.path), unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	w.path = w.path[:prefix]
+	if err != nil {
+		// hidepid or another user's process; ENOENT means it has exited.
+		if err == unix.EACCES || err == unix.EPERM {
+			st.mu.Lock()
+			st.denied = append(st.denied, pid)
+			st.mu.Unlock()
 		}
+		return
+	}
+	defer unix.Close(dirfd)
 
-		for _, fd := range fds {
-			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
-			if err != nil {
+	clear(w.held)
+	for {
+		n, err := unix.Getdents(dirfd, w.dirent)
+		if err != nil || n <= 0 {
+			break
+		}
+		for buf := w.dirent[:n]; len(buf) >= 19; {
+			// struct linux_dirent64: ino u64, off s64, reclen u16, type u8, name
+			reclen := int(*(*uint16)(unsafe.Pointer(&buf[16])))
+			if reclen == 0 || reclen > len(buf) {
+				break
+			}
+			// The name is NUL-terminated in place, so readlinkat can use it
+			// without copying.
+			name := buf[19:reclen]
+			buf = buf[reclen:]
+			if name[0] < '0' || name[0] > '9' {
+				continue
+			}
+			ino, ok := w.readSocketInode(dirfd, name)
+			if !ok {
 				continue
 			}
-			if link == target {
-				return int32(pid), nil
+			if _, want := st.inodes[ino]; want {
+				w.held[ino] = append(w.held[ino], parseFDNum(name))
 			}
 		}
 	}
-	return 0, fmt.Errorf("no process found for inode %d", inode)
+	if len(w.held) == 0 {
+		return
+	}
+
+	st.mu.Lock()
+	for ino, fds := range w.held {
+		sort.Slice(fds, func(i, j int) bool { return fds[i] < fds[j] })
+		if len(st.result[ino]) == 0 && st.remaining.Add(-1) == 0 && firstOwner {
+			st.stop.Store(true)
+		}
+		st.result[ino] = append(st.result[ino], domain.SocketOwner{PID: pid, FDs: fds})
+		delete(w.held, ino)
+	}
+	st.mu.Unlock()
 }
 
-// mapInodesToPIDs batch-maps socket inodes to PIDs.
-// Returns a map of inode -> PID. Handles hidepid gracefully by skipping
-// inaccessible /proc entries.
-func mapInodesToPIDs(inodes []uint64) map[uint64]int32 {
-	if len(inodes) == 0 {
-		return nil
+// readSocketInode reads the link of the descriptor named by the
+// NUL-terminated name and parses a "socket:[<inode>]" target.
+func (w *fdWorker) readSocketInode(dirfd int, name []byte) (uint64, bool) {
+	n, _, errno := unix.Syscall6(unix.SYS_READLINKAT, uintptr(dirfd),
+		uintptr(unsafe.Pointer(&name[0])), uintptr(unsafe.Pointer(&w.link[0])), uintptr(len(w.link)), 0, 0)
+	if errno != 0 {
+		return 0, false
 	}
+	return parseSocketLink(w.link[:n])
+}
 
-	inodeSet := make(map[uint64]bool, len(inodes))
-	for _, ino := range inodes {
-		inodeSet[ino] = true
+var socketLinkPrefix = []byte("socket:[")
+
+// parseSocketLink parses the inode out of a "socket:[<inode>]" link target.
+func parseSocketLink(link []byte) (uint64, bool) {
+	if !bytes.HasPrefix(link, socketLinkPrefix) || link[len(link)-1] != ']' {
+		return 0, false
 	}
+	digits := link[len(socketLinkPrefix) : len(link)-1]
+	if len(digits) == 0 {
+		return 0, false
+	}
+	var ino uint64
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		ino = ino*10 + uint64(c-'0')
+	}
+	return ino, true
+}
 
-	result := make(map[uint64]int32, len(inodes))
+// parseFDNum parses a NUL-terminated descriptor number.
+func parseFDNum(name []byte) int32 {
+	var fd int32
+	for _, c := range name {
+		if c < '0' || c > '9' {
+			break
+		}
+		fd = fd*10 + int32(c-'0')
+	}
+	return fd
+}
 
-	entries, err := os.ReadDir("/proc")
+// listPIDs returns the numeric entries of procRoot.
+func listPIDs(procRoot string) ([]int32, error) {
+	fd, err := unix.Open(procRoot, unix.O_RDONLY|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
 	if err != nil {
-		return result
+		return nil, err
 	}
+	defer unix.Close(fd)
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+	pids := make([]int32, 0, 512)
+	buf := make([]byte, direntBufLen)
+	for {
+		n, err := unix.Getdents(fd, buf)
 		if err != nil {
-			continue
+			return nil, err
 		}
+		if n <= 0 {
+			return pids, nil
+		}
+		for b := buf[:n]; len(b) >= 19; {
+			reclen := int(*(*uint16)(unsafe.Pointer(&b[16])))
+			if reclen == 0 || reclen > len(b) {
+				break
+			}
+			if c := b[19]; c >= '1' && c <= '9' {
+				pids = append(pids, parseFDNum(b[19:reclen]))
+			}
+			b = b[reclen:]
+		}
+	}
+}
 
-		fdDir := filepath.Join("/proc", entry.Name(), "fd")
-		fds, err := os.ReadDir(fdDir)
-		if err != nil {
-			// hidepid mount option or permission denied — skip gracefully
+// mapInodeToPID finds a process holding the given socket inode.
+func mapInodeToPID(inode uint64) (int32, error) {
+	s := newFDScanner()
+	s.firstOwner = true
+	owners, _ := s.scan([]uint64{inode})
+	if o := owners[inode]; len(o) > 0 {
+		return o[0].PID, nil
+	}
+	return 0, fmt.Errorf("no process found for inode %d", inode)
+}
+
+// mapInodesToOwners batch-maps socket inodes to every process holding them.
+// A listening socket inherited by pre-forked workers is held by several
+// PIDs, so the whole of /proc is scanned rather than stopping at the first
+// match. Owners are sorted by PID. When pids is non-empty only those
+// processes are scanned, so sockets they share with others list just them.
+// Inaccessible /proc entries (hidepid) are skipped; their number is
+// returned alongside.
+func mapInodesToOwners(procRoot string, inodes []uint64, pids []int32) (map[uint64][]domain.SocketOwner, int) {
+	s := newFDScanner()
+	s.procRoot = procRoot
+	if len(pids) > 0 {
+		s.pids = pids
+	}
+	owners, denied := s.scan(inodes)
+	return owners, len(denied)
+}
+
+// procHidesPIDs reports whether the procfs at procRoot is mounted with
+// hidepid, under which other users' processes are not even listed.
+func procHidesPIDs(procRoot string) bool {
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "mountinfo"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		// ... mount-point ... - fstype source super-options
+		fields := strings.Fields(line)
+		if len(fields) < 5 || fields[4] != procRoot {
 			continue
 		}
-
-		for _, fd := range fds {
-			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
-			if err != nil {
-				continue
-			}
-			if strings.HasPrefix(link, "socket:[") {
-				inoStr := link[8 : len(link)-1]
-				ino, err := strconv.ParseUint(inoStr, 10, 64)
-				if err != nil {
-					continue
-				}
-				if inodeSet[ino] {
-					result[ino] = int32(pid)
-					delete(inodeSet, ino)
-					if len(inodeSet) == 0 {
-						return result
-					}
-				}
+		for _, opt := range strings.Split(fields[len(fields)-1], ",") {
+			if v, ok := strings.CutPrefix(opt, "hidepid="); ok && v != "0" && v != "off" {
+				return true
 			}
 		}
 	}
-	return result
+	return false
 }
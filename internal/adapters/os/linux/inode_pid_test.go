@@ -0,0 +1,334 @@
+//go:build linux
+
+package linux
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// scanSerial is the straightforward os.ReadDir/os.Readlink walk fdScanner
+// replaced. It is the reference for correctness and the benchmark baseline.
+func scanSerial(inodes []uint64) map[uint64][]domain.SocketOwner {
+	inodeSet := make(map[uint64]bool, len(inodes))
+	for _, ino := range inodes {
+		inodeSet[ino] = true
+	}
+	result := make(map[uint64][]domain.SocketOwner, len(inodes))
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return result
+	}
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		held := make(map[uint64][]int32)
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			ino, err := strconv.ParseUint(link[8:len(link)-1], 10, 64)
+			if err != nil || !inodeSet[ino] {
+				continue
+			}
+			fdNum, _ := strconv.ParseInt(fd.Name(), 10, 32)
+			held[ino] = append(held[ino], int32(fdNum))
+		}
+		for ino, fdNums := range held {
+			sort.Slice(fdNums, func(i, j int) bool { return fdNums[i] < fdNums[j] })
+			result[ino] = append(result[ino], domain.SocketOwner{PID: int32(pid), FDs: fdNums})
+		}
+	}
+	for _, owners := range result {
+		sort.Slice(owners, func(i, j int) bool { return owners[i].PID < owners[j].PID })
+	}
+	return result
+}
+
+// socketInodes returns the inodes of every socket in /proc/net/tcp{,6}.
+func socketInodes(t testing.TB) []uint64 {
+	var inodes []uint64
+	for _, src := range inetSources[:2] {
+		entries, err := parseProcNet(src, filepath.Join("/proc/net", src.procFile), false)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.inode != 0 {
+				inodes = append(inodes, e.inode)
+			}
+		}
+	}
+	if len(inodes) == 0 {
+		t.Skip("no TCP sockets to scan for")
+	}
+	return inodes
+}
+
+func listenerInode(t *testing.T) (net.Listener, uint64) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln, fi.Sys().(*syscall.Stat_t).Ino
+}
+
+func TestFDScanner_MatchesSerialScan(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	inodes := socketInodes(t)
+	got, _ := mapInodesToOwners(defaultProcRoot, inodes, nil)
+	want := scanSerial(inodes)
+	// Short-lived processes may come and go between the two scans, so only
+	// compare our own sockets, which are stable.
+	self := int32(os.Getpid())
+	for _, ino := range inodes {
+		if !reflect.DeepEqual(ownedBy(want[ino], self), ownedBy(got[ino], self)) {
+			t.Errorf("inode %d: parallel %v, serial %v", ino, got[ino], want[ino])
+		}
+	}
+	if len(got) == 0 {
+		t.Error("expected at least our own listener to be attributed")
+	}
+}
+
+func ownedBy(owners []domain.SocketOwner, pid int32) []int32 {
+	for _, o := range owners {
+		if o.PID == pid {
+			return o.FDs
+		}
+	}
+	return nil
+}
+
+func TestFDScanner_RestrictToPIDs(t *testing.T) {
+	ln, ino := listenerInode(t)
+	defer ln.Close()
+	self := int32(os.Getpid())
+
+	owners, _ := mapInodesToOwners(defaultProcRoot, []uint64{ino}, []int32{self})
+	if len(owners[ino]) != 1 || owners[ino][0].PID != self {
+		t.Fatalf("expected only PID %d, got %v", self, owners[ino])
+	}
+
+	owners, _ = mapInodesToOwners(defaultProcRoot, []uint64{ino}, []int32{1 << 30})
+	if len(owners[ino]) != 0 {
+		t.Errorf("expected no owners outside the PID set, got %v", owners[ino])
+	}
+}
+
+func TestFDScanner_FirstOwner(t *testing.T) {
+	ln, ino := listenerInode(t)
+	defer ln.Close()
+
+	pid, err := mapInodeToPID(ino)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != int32(os.Getpid()) {
+		t.Errorf("expected PID %d, got %d", os.Getpid(), pid)
+	}
+	if _, err := mapInodeToPID(1<<63 - 1); err == nil {
+		t.Error("expected an error for an inode nobody holds")
+	}
+}
+
+func TestParseSocketLink(t *testing.T) {
+	tests := []struct {
+		link string
+		ino  uint64
+		ok   bool
+	}{
+		{"socket:[12345]", 12345, true},
+		{"socket:[]", 0, false},
+		{"socket:[12a]", 0, false},
+		{"pipe:[12345]", 0, false},
+		{"/dev/null", 0, false},
+		{"anon_inode:[eventpoll]", 0, false},
+	}
+	for _, tt := range tests {
+		ino, ok := parseSocketLink([]byte(tt.link))
+		if ino != tt.ino || ok != tt.ok {
+			t.Errorf("parseSocketLink(%q) = %d, %v; want %d, %v", tt.link, ino, ok, tt.ino, tt.ok)
+		}
+	}
+}
+
+func TestListPIDs(t *testing.T) {
+	pids, err := listPIDs("/proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !containsPID(pids, int32(os.Getpid())) {
+		t.Errorf("expected our PID %d among %d PIDs", os.Getpid(), len(pids))
+	}
+}
+
+func containsPID(pids []int32, pid int32) bool {
+	for _, p := range pids {
+		if p == pid {
+			return true
+		}
+	}
+	return false
+}
+
+func BenchmarkInodeScan_Serial(b *testing.B) {
+	inodes := socketInodes(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		scanSerial(inodes)
+	}
+}
+
+func BenchmarkInodeScan_Parallel(b *testing.B) {
+	inodes := socketInodes(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		mapInodesToOwners(defaultProcRoot, inodes, nil)
+	}
+}
+
+func BenchmarkInodeScan_SingleWorker(b *testing.B) {
+	inodes := socketInodes(b)
+	s := newFDScanner()
+	s.workers = 1
+	b.ReportAllocs()
+	for b.Loop() {
+		s.scan(inodes)
+	}
+}
+
+func TestOwnerCache_Incremental(t *testing.T) {
+	self := int32(os.Getpid())
+	c := newOwnerCache("/proc")
+
+	ln1, ino1 := listenerInode(t)
+	owners, _ := c.lookup([]uint64{ino1})
+	if len(owners[ino1]) == 0 || owners[ino1][0].PID != self {
+		t.Fatalf("first lookup: expected PID %d, got %v", self, owners[ino1])
+	}
+
+	ln2, ino2 := listenerInode(t)
+	defer ln2.Close()
+	owners, _ = c.lookup([]uint64{ino1, ino2})
+	if ownedBy(owners[ino1], self) == nil || ownedBy(owners[ino2], self) == nil {
+		t.Fatalf("expected both listeners attributed, got %v", owners)
+	}
+
+	ln1.Close()
+	if _, ok := c.owners[ino2]; !ok {
+		t.Fatal("expected the cache to remember the second listener")
+	}
+	c.lookup([]uint64{ino2})
+	if _, ok := c.owners[ino1]; ok {
+		t.Error("expected the closed listener to be dropped from the cache")
+	}
+
+	// A socket opened while our descriptor count looks unchanged (one closed,
+	// one opened) is still found by the fallback scan.
+	ln3, ino3 := listenerInode(t)
+	defer ln3.Close()
+	c.procs[self] = c.procState(self)
+	owners, _ = c.lookup([]uint64{ino2, ino3})
+	if ownedBy(owners[ino3], self) == nil {
+		t.Errorf("expected the new listener to be found, got %v", owners[ino3])
+	}
+}
+
+func TestOwnerCache_PeekLeavesCacheAlone(t *testing.T) {
+	self := int32(os.Getpid())
+	c := newOwnerCache("/proc")
+
+	ln1, ino1 := listenerInode(t)
+	defer ln1.Close()
+	c.lookup([]uint64{ino1})
+	state := c.procs[self]
+
+	ln2, ino2 := listenerInode(t)
+	defer ln2.Close()
+	owners, _ := c.peek([]uint64{ino2})
+	if ownedBy(owners[ino2], self) == nil {
+		t.Fatalf("peek: expected PID %d, got %v", self, owners[ino2])
+	}
+	if _, ok := c.owners[ino2]; ok || c.procs[self] != state {
+		t.Error("peek updated the cache")
+	}
+
+	// The next lookup still rescans us and finds both listeners.
+	owners, _ = c.lookup([]uint64{ino1, ino2})
+	if ownedBy(owners[ino1], self) == nil || ownedBy(owners[ino2], self) == nil {
+		t.Errorf("expected both listeners attributed after peek, got %v", owners)
+	}
+}
+
+func TestOwnerCache_RescansWithoutFDCountOrOnNewStartTime(t *testing.T) {
+	self := int32(os.Getpid())
+	c := newOwnerCache("/proc")
+	c.fdDirSize = func(string) int64 { return 0 } // Linux before 6.2
+
+	ln, ino := listenerInode(t)
+	defer ln.Close()
+	c.lookup([]uint64{ino})
+
+	// Forget the owner: only a rescan of our unchanged-looking process can
+	// find it again, since the inode is no longer new.
+	c.owners[ino] = nil
+	owners, _ := c.lookup([]uint64{ino})
+	if ownedBy(owners[ino], self) == nil {
+		t.Fatalf("fd dir size 0: expected PID %d to be rescanned, got %v", self, owners[ino])
+	}
+
+	// With a real count, a different start time means the PID was reused.
+	c.fdDirSize = statSize
+	c.lookup([]uint64{ino})
+	c.owners[ino] = nil
+	prev := c.procs[self]
+	prev.start++
+	c.procs[self] = prev
+	owners, _ = c.lookup([]uint64{ino})
+	if ownedBy(owners[ino], self) == nil {
+		t.Errorf("new start time: expected PID %d to be rescanned, got %v", self, owners[ino])
+	}
+}
+
+func BenchmarkInodeScan_Incremental(b *testing.B) {
+	inodes := socketInodes(b)
+	c := newOwnerCache("/proc")
+	c.lookup(inodes)
+	b.ReportAllocs()
+	for b.Loop() {
+		c.lookup(inodes)
+	}
+}
@@ -0,0 +1,98 @@
+//go:build linux
+
+package linux
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+const (
+	inetDiagMsgLen = 72
+	inetDiagInfo   = 2 // INET_DIAG_INFO extension carrying struct tcp_info
+)
+
+// tcp_info field offsets (include/uapi/linux/tcp.h). Older kernels send a
+// shorter struct, so every read is bounds-checked.
+const (
+	tcpiLastDataRecv = 52
+	tcpiRTT          = 68
+	tcpiRTTVar       = 72
+	tcpiSndCwnd      = 80
+	tcpiTotalRetrans = 100
+	tcpiBytesAcked   = 120
+	tcpiBytesRecv    = 128
+	tcpiBytesSent    = 200
+)
+
+// parseDiagMetrics extracts queue depths from an inet_diag_msg and, when
+// present, TCP statistics from its INET_DIAG_INFO attribute.
+func parseDiagMetrics(data []byte) *domain.ConnMetrics {
+	m := &domain.ConnMetrics{
+		RecvQueue: binary.LittleEndian.Uint32(data[56:60]),
+		SendQueue: binary.LittleEndian.Uint32(data[60:64]),
+	}
+	if info, ok := parseAttrs(data[inetDiagMsgLen:])[inetDiagInfo]; ok {
+		parseTCPInfo(info, m)
+	}
+	return m
+}
+
+func parseTCPInfo(info []byte, m *domain.ConnMetrics) {
+	u32 := func(off int) (uint32, bool) {
+		if off+4 > len(info) {
+			return 0, false
+		}
+		return binary.LittleEndian.Uint32(info[off : off+4]), true
+	}
+	u64 := func(off int) (uint64, bool) {
+		if off+8 > len(info) {
+			return 0, false
+		}
+		return binary.LittleEndian.Uint64(info[off : off+8]), true
+	}
+
+	rtt, ok := u32(tcpiRTT)
+	if !ok {
+		return
+	}
+	m.HasTCPInfo = true
+	m.RTT = time.Duration(rtt) * time.Microsecond
+	if v, ok := u32(tcpiRTTVar); ok {
+		m.RTTVar = time.Duration(v) * time.Microsecond
+	}
+	if v, ok := u32(tcpiLastDataRecv); ok {
+		m.LastDataRecv = time.Duration(v) * time.Millisecond
+	}
+	if v, ok := u32(tcpiSndCwnd); ok {
+		m.Cwnd = v
+	}
+	if v, ok := u32(tcpiTotalRetrans); ok {
+		m.Retransmits = v
+	}
+	if v, ok := u64(tcpiBytesAcked); ok {
+		m.BytesAcked = v
+	}
+	if v, ok := u64(tcpiBytesRecv); ok {
+		m.BytesReceived = v
+	}
+	if v, ok := u64(tcpiBytesSent); ok {
+		m.BytesSent = v
+	}
+}
+
+// parseProcQueues parses the "tx_queue:rx_queue" column of /proc/net/{tcp,udp}.
+// tcp_info is not exposed there, so only queue depths are available.
+func parseProcQueues(field string) *domain.ConnMetrics {
+	tx, rx, ok := strings.Cut(field, ":")
+	if !ok {
+		return nil
+	}
+	txq, _ := strconv.ParseUint(tx, 16, 32)
+	rxq, _ := strconv.ParseUint(rx, 16, 32)
+	return &domain.ConnMetrics{SendQueue: uint32(txq), RecvQueue: uint32(rxq)}
+}
@@ -0,0 +1,242 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+const (
+	nlmsgHdrLen       = 16
+	sockDiagByFamily  = 20
+	netlinkRecvBufLen = 65536
+	netlinkMaxBufLen  = 1 << 20
+
+	// maxDumpAttempts bounds how often a dump is restarted after the kernel
+	// flags it as interrupted (the socket table changed mid-dump) or a reply
+	// did not fit the receive buffer.
+	maxDumpAttempts = 4
+
+	// netlinkPollInterval is how often a blocked receive wakes up to check
+	// for cancellation.
+	netlinkPollInterval = 100 * time.Millisecond
+)
+
+var (
+	// errNetlinkSkipped marks a sock_diag dump that was deliberately not
+	// attempted, so callers go straight to their /proc fallback.
+	errNetlinkSkipped = errors.New("netlink not attempted")
+
+	errDumpInterrupted = errors.New("sock_diag dump kept being interrupted by socket table changes")
+	errDumpTruncated   = errors.New("sock_diag reply truncated")
+)
+
+var netlinkSeq atomic.Uint32
+
+// nlConn is the transport a dump runs over: a NETLINK_SOCK_DIAG socket, or
+// scripted replies in tests. recv reports the full length of the datagram,
+// which exceeds len(buf) when it was truncated.
+type nlConn interface {
+	send(req []byte) error
+	recv(buf []byte) (int, error)
+	close() error
+}
+
+type sockDiagConn struct{ fd int }
+
+func dialSockDiag() (nlConn, error) {
+	return dialNetlink(unix.NETLINK_SOCK_DIAG)
+}
+
+// dialNetlink opens a netlink socket of the given protocol whose receives
+// time out every netlinkPollInterval.
+func dialNetlink(proto int) (*sockDiagConn, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, proto)
+	if err != nil {
+		return nil, err
+	}
+	tv := unix.NsecToTimeval(netlinkPollInterval.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &sockDiagConn{fd: fd}, nil
+}
+
+func (c *sockDiagConn) send(req []byte) error {
+	return unix.Sendto(c.fd, req, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+}
+
+func (c *sockDiagConn) recv(buf []byte) (int, error) {
+	n, _, err := unix.Recvfrom(c.fd, buf, unix.MSG_TRUNC)
+	return n, err
+}
+
+func (c *sockDiagConn) close() error { return unix.Close(c.fd) }
+
+// netlinkDump sends a sock_diag dump request and invokes fn with the payload
+// of every response message until NLMSG_DONE. When the dump has to be
+// restarted, reset is called first so fn's results can be discarded.
+func netlinkDump(ctx context.Context, req []byte, reset func(), fn func(data []byte)) error {
+	return dumpWith(ctx, dialSockDiag, req, reset, fn)
+}
+
+// dumpWith runs the dump over connections from dial, a fresh one for each
+// attempt since the kernel runs one dump per socket at a time.
+func dumpWith(ctx context.Context, dial func() (nlConn, error), req []byte, reset func(), fn func(data []byte)) error {
+	buf := make([]byte, netlinkRecvBufLen)
+	err := errDumpInterrupted
+	for attempt := 0; attempt < maxDumpAttempts; attempt++ {
+		if attempt > 0 {
+			reset()
+		}
+		var conn nlConn
+		if conn, err = dial(); err != nil {
+			return err
+		}
+		err = dumpOnce(ctx, conn, req, buf, fn)
+		conn.close()
+		if errors.Is(err, errDumpTruncated) && len(buf) < netlinkMaxBufLen {
+			buf = make([]byte, 4*len(buf))
+			continue
+		}
+		if !errors.Is(err, errDumpInterrupted) {
+			return err
+		}
+	}
+	return err
+}
+
+// dumpOnce runs one dump. Payloads of an interrupted dump are not passed
+// on, since the attempt is going to be discarded.
+func dumpOnce(ctx context.Context, conn nlConn, req, buf []byte, fn func(data []byte)) error {
+	seq := netlinkSeq.Add(1)
+	binary.LittleEndian.PutUint32(req[8:12], seq)
+	if err := conn.send(req); err != nil {
+		return err
+	}
+
+	interrupted := false
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := conn.recv(buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("sock_diag receive: %w", err)
+		}
+		if n > len(buf) {
+			return fmt.Errorf("%w: %d byte message, %d byte buffer", errDumpTruncated, n, len(buf))
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return fmt.Errorf("sock_diag reply malformed: %w", err)
+		}
+		for _, msg := range msgs {
+			if msg.Header.Seq != seq {
+				continue // not a reply to this request
+			}
+			if msg.Header.Flags&unix.NLM_F_DUMP_INTR != 0 {
+				interrupted = true
+			}
+			switch msg.Header.Type {
+			case syscall.NLMSG_DONE:
+				// A dump for a protocol without a sock_diag handler (raw_diag
+				// not built, say) ends with a negative errno in NLMSG_DONE.
+				if err := netlinkErrno(msg.Data); err != nil {
+					return err
+				}
+				if interrupted {
+					return errDumpInterrupted
+				}
+				return nil
+			case syscall.NLMSG_ERROR:
+				if err := netlinkErrno(msg.Data); err != nil {
+					return err
+				}
+				return nil // a bare ACK
+			}
+			if !interrupted {
+				fn(msg.Data)
+			}
+		}
+	}
+}
+
+// netlinkErrno decodes the int32 error that leads NLMSG_ERROR and NLMSG_DONE
+// payloads. Permission errors wrap domain.ErrPermissionDenied.
+func netlinkErrno(data []byte) error {
+	if len(data) < 4 {
+		return nil
+	}
+	code := int32(binary.LittleEndian.Uint32(data))
+	if code >= 0 {
+		return nil
+	}
+	errno := syscall.Errno(-code)
+	if errno == unix.EPERM || errno == unix.EACCES {
+		return fmt.Errorf("%w: sock_diag: %w", domain.ErrPermissionDenied, errno)
+	}
+	return fmt.Errorf("sock_diag: %w", errno)
+}
+
+// netlinkUnavailable reports whether err means sock_diag cannot serve a
+// protocol at all (no handler, no netlink), as opposed to a dump failing.
+// Falling back to /proc is routine then and not worth a warning.
+func netlinkUnavailable(err error) bool {
+	return errors.Is(err, errNetlinkSkipped) || errors.Is(err, unix.ENOENT) ||
+		errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.EPROTONOSUPPORT) ||
+		errors.Is(err, unix.EAFNOSUPPORT)
+}
+
+// fallbackWarning describes a failed dump whose sockets are being read from
+// /proc instead, or returns "" when the fallback is routine.
+func fallbackWarning(label string, err error) string {
+	if netlinkUnavailable(err) {
+		return ""
+	}
+	return label + " sock_diag dump failed, read /proc instead: " + err.Error()
+}
+
+// newDiagReq allocates a SOCK_DIAG_BY_FAMILY dump request with room for a
+// family-specific request body of bodyLen bytes.
+func newDiagReq(bodyLen int) []byte {
+	buf := make([]byte, nlmsgHdrLen+bodyLen)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(buf)))
+	binary.LittleEndian.PutUint16(buf[4:6], sockDiagByFamily)
+	binary.LittleEndian.PutUint16(buf[6:8], unix.NLM_F_DUMP|unix.NLM_F_REQUEST)
+	return buf
+}
+
+// parseAttrs splits a run of rtattr-encoded netlink attributes into a map
+// keyed by attribute type.
+func parseAttrs(b []byte) map[uint16][]byte {
+	attrs := make(map[uint16][]byte)
+	for len(b) >= 4 {
+		l := int(binary.LittleEndian.Uint16(b[0:2]))
+		typ := binary.LittleEndian.Uint16(b[2:4])
+		if l < 4 || l > len(b) {
+			break
+		}
+		attrs[typ] = b[4:l]
+		aligned := (l + 3) &^ 3
+		if aligned > len(b) {
+			break
+		}
+		b = b[aligned:]
+	}
+	return attrs
+}
@@ -0,0 +1,202 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// nlMsg is one scripted reply message; seqOffset is added to the sequence
+// number of the request it answers.
+type nlMsg struct {
+	typ       uint16
+	flags     uint16
+	seqOffset int
+	payload   []byte
+}
+
+// scriptedConn replays one datagram per recv, each built from the sequence
+// number of the last request sent.
+type scriptedConn struct {
+	datagrams [][]nlMsg
+	seq       uint32
+	sent      int
+}
+
+func (c *scriptedConn) send(req []byte) error {
+	c.seq = binary.LittleEndian.Uint32(req[8:12])
+	c.sent++
+	return nil
+}
+
+func (c *scriptedConn) recv(buf []byte) (int, error) {
+	if len(c.datagrams) == 0 {
+		return 0, unix.EAGAIN
+	}
+	var dgram []byte
+	for _, m := range c.datagrams[0] {
+		dgram = append(dgram, encodeNlMsg(m, uint32(int(c.seq)+m.seqOffset))...)
+	}
+	c.datagrams = c.datagrams[1:]
+	return copy(buf, dgram) + max(len(dgram)-len(buf), 0), nil
+}
+
+func (c *scriptedConn) close() error { return nil }
+
+func encodeNlMsg(m nlMsg, seq uint32) []byte {
+	l := nlmsgHdrLen + len(m.payload)
+	b := make([]byte, (l+3)&^3)
+	binary.LittleEndian.PutUint32(b[0:4], uint32(l))
+	binary.LittleEndian.PutUint16(b[4:6], m.typ)
+	binary.LittleEndian.PutUint16(b[6:8], m.flags)
+	binary.LittleEndian.PutUint32(b[8:12], seq)
+	copy(b[nlmsgHdrLen:], m.payload)
+	return b
+}
+
+func errnoPayload(errno syscall.Errno) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(-int32(errno)))
+	return b
+}
+
+var nlDone = nlMsg{typ: syscall.NLMSG_DONE, payload: make([]byte, 4)}
+
+func nlData(s string) nlMsg {
+	return nlMsg{typ: sockDiagByFamily, flags: unix.NLM_F_MULTI, payload: []byte(s)}
+}
+
+// runScripted dumps over one scriptedConn per attempt and returns the
+// payloads that survived, the number of connections dialled and the error.
+func runScripted(ctx context.Context, attempts ...*scriptedConn) ([]string, int, error) {
+	var got []string
+	dialled := 0
+	dial := func() (nlConn, error) {
+		c := attempts[dialled]
+		dialled++
+		return c, nil
+	}
+	reset := func() { got = got[:0] }
+	err := dumpWith(ctx, dial, newDiagReq(8), reset, func(data []byte) {
+		got = append(got, strings.TrimRight(string(data), "\x00"))
+	})
+	return got, dialled, err
+}
+
+func TestNetlinkDump_DecodesErrno(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      nlMsg
+		errno    syscall.Errno
+		permDeny bool
+	}{
+		{"error EPERM", nlMsg{typ: syscall.NLMSG_ERROR, payload: errnoPayload(unix.EPERM)}, unix.EPERM, true},
+		{"error EACCES", nlMsg{typ: syscall.NLMSG_ERROR, payload: errnoPayload(unix.EACCES)}, unix.EACCES, true},
+		{"done ENOENT", nlMsg{typ: syscall.NLMSG_DONE, payload: errnoPayload(unix.ENOENT)}, unix.ENOENT, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runScripted(context.Background(), &scriptedConn{datagrams: [][]nlMsg{{tt.msg}}})
+			if !errors.Is(err, tt.errno) {
+				t.Fatalf("err = %v, want %v", err, tt.errno)
+			}
+			if errors.Is(err, domain.ErrPermissionDenied) != tt.permDeny {
+				t.Errorf("errors.Is(%v, ErrPermissionDenied) = %v, want %v", err, !tt.permDeny, tt.permDeny)
+			}
+		})
+	}
+}
+
+func TestNetlinkDump_RetriesInterruptedDump(t *testing.T) {
+	first := &scriptedConn{datagrams: [][]nlMsg{
+		{nlData("a1")},
+		{{typ: sockDiagByFamily, flags: unix.NLM_F_MULTI | unix.NLM_F_DUMP_INTR, payload: []byte("a2")}, nlDone},
+	}}
+	second := &scriptedConn{datagrams: [][]nlMsg{{nlData("b1"), nlData("b2"), nlDone}}}
+
+	got, dialled, err := runScripted(context.Background(), first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dialled != 2 {
+		t.Errorf("dialled %d connections, want 2", dialled)
+	}
+	if strings.Join(got, ",") != "b1,b2" {
+		t.Errorf("got %v, want only the second attempt's payloads", got)
+	}
+}
+
+func TestNetlinkDump_GivesUpOnPersistentInterruption(t *testing.T) {
+	var conns []*scriptedConn
+	for range maxDumpAttempts {
+		conns = append(conns, &scriptedConn{datagrams: [][]nlMsg{
+			{{typ: syscall.NLMSG_DONE, flags: unix.NLM_F_DUMP_INTR, payload: make([]byte, 4)}},
+		}})
+	}
+	_, dialled, err := runScripted(context.Background(), conns...)
+	if !errors.Is(err, errDumpInterrupted) {
+		t.Fatalf("err = %v, want errDumpInterrupted", err)
+	}
+	if dialled != maxDumpAttempts {
+		t.Errorf("dialled %d connections, want %d", dialled, maxDumpAttempts)
+	}
+}
+
+func TestNetlinkDump_GrowsBufferOnTruncation(t *testing.T) {
+	big := nlData(strings.Repeat("x", netlinkRecvBufLen))
+	first := &scriptedConn{datagrams: [][]nlMsg{{big, nlDone}}}
+	second := &scriptedConn{datagrams: [][]nlMsg{{big, nlDone}}}
+
+	got, dialled, err := runScripted(context.Background(), first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dialled != 2 || len(got) != 1 || len(got[0]) != netlinkRecvBufLen {
+		t.Errorf("dialled %d, got %d payloads; want the message intact on the second attempt", dialled, len(got))
+	}
+}
+
+func TestNetlinkDump_SkipsStaleReplies(t *testing.T) {
+	conn := &scriptedConn{datagrams: [][]nlMsg{
+		{{typ: sockDiagByFamily, seqOffset: -1, payload: []byte("stale")}, nlData("fresh")},
+		{{typ: syscall.NLMSG_DONE, seqOffset: -1, payload: make([]byte, 4)}, nlDone},
+	}}
+	got, _, err := runScripted(context.Background(), conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "fresh" {
+		t.Errorf("got %v, want [fresh]", got)
+	}
+}
+
+func TestNetlinkDump_HonoursCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// No datagrams: without the context check the dump would spin forever.
+	_, _, err := runScripted(ctx, &scriptedConn{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestFallbackWarning(t *testing.T) {
+	for _, err := range []error{errNetlinkSkipped, netlinkErrno(errnoPayload(unix.ENOENT)), unix.EPROTONOSUPPORT} {
+		if w := fallbackWarning("TCP", err); w != "" {
+			t.Errorf("fallbackWarning(%v) = %q, want none", err, w)
+		}
+	}
+	w := fallbackWarning("TCP", netlinkErrno(errnoPayload(unix.EPERM)))
+	if !strings.HasPrefix(w, "TCP sock_diag dump failed") || !strings.Contains(w, "permission denied") {
+		t.Errorf("fallbackWarning(EPERM) = %q", w)
+	}
+}
@@ -0,0 +1,203 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+const namedNetnsDir = "/run/netns"
+
+// netnsTarget is one network namespace to enumerate and the handles used to
+// reach it.
+type netnsTarget struct {
+	ns     domain.NetNamespace
+	nsPath string // file to setns(2) into
+	host   bool   // the namespace porthog itself runs in
+}
+
+// procNet returns the <procRoot>/<pid>/net directory of the namespace's
+// representative process, which can be read without entering it.
+func (t *netnsTarget) procNet(procRoot string) string {
+	if t.ns.PID == 0 {
+		return ""
+	}
+	return filepath.Join(procRoot, strconv.Itoa(int(t.ns.PID)), "net")
+}
+
+// discoverNamespaces groups every readable <procRoot>/<pid>/ns/net by inode
+// and adds namespaces that are only pinned by a name under /run/netns.
+// Processes whose namespace link cannot be read (other users' processes when
+// not root) are skipped.
+func discoverNamespaces(procRoot string) ([]netnsTarget, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	self, _ := netnsInode("/proc/self/ns/net")
+	names := namedNamespaces()
+
+	byInode := make(map[uint64]*netnsTarget)
+	for _, entry := range entries {
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		nsPath := filepath.Join(procRoot, entry.Name(), "ns", "net")
+		ino, err := netnsInode(nsPath)
+		if err != nil {
+			continue
+		}
+		// os.ReadDir sorts lexically, so keep the numerically lowest PID.
+		if t, ok := byInode[ino]; ok && t.ns.PID < int32(pid) {
+			continue
+		}
+		byInode[ino] = &netnsTarget{
+			ns:     domain.NetNamespace{Inode: ino, Name: names[ino], PID: int32(pid)},
+			nsPath: nsPath,
+			host:   ino == self,
+		}
+	}
+	for ino, name := range names {
+		if _, ok := byInode[ino]; !ok {
+			byInode[ino] = &netnsTarget{
+				ns:     domain.NetNamespace{Inode: ino, Name: name},
+				nsPath: filepath.Join(namedNetnsDir, name),
+			}
+		}
+	}
+
+	targets := make([]netnsTarget, 0, len(byInode))
+	for _, t := range byInode {
+		t.ns.Process = readComm(procRoot, t.ns.PID)
+		targets = append(targets, *t)
+	}
+	// Host namespace first, then by inode for stable output.
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].host != targets[j].host {
+			return targets[i].host
+		}
+		return targets[i].ns.Inode < targets[j].ns.Inode
+	})
+	return targets, nil
+}
+
+// resolveNamespace finds the namespace named by a --netns value: the PID of
+// a process inside it, a name under /run/netns, or a path to an nsfs file.
+func resolveNamespace(procRoot, spec string) (netnsTarget, error) {
+	var nsPath string
+	if _, err := strconv.ParseInt(spec, 10, 32); err == nil {
+		nsPath = filepath.Join(procRoot, spec, "ns", "net")
+	} else if strings.ContainsRune(spec, '/') {
+		nsPath = spec
+	} else {
+		nsPath = filepath.Join(namedNetnsDir, spec)
+	}
+	ino, err := netnsInode(nsPath)
+	if err != nil {
+		return netnsTarget{}, fmt.Errorf("network namespace %q: %w", spec, err)
+	}
+
+	// Look the inode up among live processes for a representative PID, which
+	// enables the /proc/<pid>/net fallback and labels the output.
+	all, err := discoverNamespaces(procRoot)
+	if err == nil {
+		for _, t := range all {
+			if t.ns.Inode == ino {
+				return t, nil
+			}
+		}
+	}
+	self, _ := netnsInode("/proc/self/ns/net")
+	return netnsTarget{
+		ns:     domain.NetNamespace{Inode: ino, Name: namedNamespaces()[ino]},
+		nsPath: nsPath,
+		host:   ino == self,
+	}, nil
+}
+
+// netnsInode returns the nsfs inode of a namespace file or magic link.
+func netnsInode(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, err
+	}
+	return st.Ino, nil
+}
+
+// namedNamespaces maps the inodes of namespaces created with `ip netns add`
+// to their names.
+func namedNamespaces() map[uint64]string {
+	entries, err := os.ReadDir(namedNetnsDir)
+	if err != nil {
+		return nil
+	}
+	names := make(map[uint64]string, len(entries))
+	for _, entry := range entries {
+		if ino, err := netnsInode(filepath.Join(namedNetnsDir, entry.Name())); err == nil {
+			names[ino] = entry.Name()
+		}
+	}
+	return names
+}
+
+func readComm(procRoot string, pid int32) string {
+	if pid == 0 {
+		return ""
+	}
+	b, err := os.ReadFile(filepath.Join(procRoot, strconv.Itoa(int(pid)), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// inNamespace runs fn on an OS thread that has joined the network namespace
+// at nsPath. Sockets fn opens, including sock_diag netlink sockets, belong to
+// that namespace. fn must not hand work to other goroutines.
+func inNamespace(nsPath string, fn func()) error {
+	errc := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		orig, err := os.Open("/proc/thread-self/ns/net")
+		if err != nil {
+			runtime.UnlockOSThread()
+			errc <- err
+			return
+		}
+		defer orig.Close()
+		target, err := os.Open(nsPath)
+		if err != nil {
+			runtime.UnlockOSThread()
+			errc <- err
+			return
+		}
+		defer target.Close()
+
+		if err := unix.Setns(int(target.Fd()), unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			errc <- fmt.Errorf("setns %s: %w", nsPath, err)
+			return
+		}
+		fn()
+		// If the thread cannot be switched back it stays locked, and the
+		// runtime discards it when this goroutine exits.
+		if err := unix.Setns(int(orig.Fd()), unix.CLONE_NEWNET); err == nil {
+			runtime.UnlockOSThread()
+		}
+		errc <- nil
+	}()
+	return <-errc
+}
@@ -0,0 +1,222 @@
+//go:build linux
+
+package linux
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// ownerCache makes repeated inode-to-owner lookups incremental. It keeps the
+// owners found by the previous lookup and, per process, its start time and
+// the size the kernel reports for /proc/<pid>/fd (its number of open
+// descriptors). On the next lookup only processes that are new, were
+// replaced by another process with the same PID, or whose descriptor count
+// changed are rescanned; the others keep their previous sockets. Kernels
+// before 6.2 report a size of 0, which tells nothing, so there every process
+// is rescanned each time.
+//
+// A process that closes one socket and opens another between lookups keeps
+// the same count, so a socket that is new and still has no owner after the
+// incremental pass triggers a scan of the remaining processes for it.
+type ownerCache struct {
+	mu        sync.Mutex
+	procRoot  string
+	owners    map[uint64][]domain.SocketOwner // every inode seen, nil when unowned
+	procs     map[int32]procFDs
+	denied    map[int32]bool
+	fdDirSize func(dir string) int64 // stat size of a /proc/<pid>/fd directory, -1 on error
+}
+
+// procFDs is what ownerCache remembers about a process between lookups.
+type procFDs struct {
+	start uint64 // field 22 of /proc/<pid>/stat, in clock ticks since boot
+	count int64  // size of /proc/<pid>/fd
+}
+
+func newOwnerCache(procRoot string) *ownerCache {
+	return &ownerCache{
+		procRoot:  procRoot,
+		owners:    make(map[uint64][]domain.SocketOwner),
+		procs:     make(map[int32]procFDs),
+		denied:    make(map[int32]bool),
+		fdDirSize: statSize,
+	}
+}
+
+// lookup is mapInodesToOwners against the cache, which it then replaces with
+// the result.
+func (c *ownerCache) lookup(inodes []uint64) (map[uint64][]domain.SocketOwner, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.find(inodes, true)
+}
+
+// peek is lookup for a few sockets that appeared since the last one. It
+// rescans the same processes but leaves the cache alone, since what it
+// found about the other sockets is incomplete: the next lookup still sees
+// the processes as changed and the sockets as new.
+func (c *ownerCache) peek(inodes []uint64) (map[uint64][]domain.SocketOwner, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.find(inodes, false)
+}
+
+// find maps inodes to their owners, updating the cache when commit is set.
+func (c *ownerCache) find(inodes []uint64, commit bool) (map[uint64][]domain.SocketOwner, int) {
+	pids, err := listPIDs(c.procRoot)
+	if err != nil {
+		return mapInodesToOwners(c.procRoot, inodes, nil)
+	}
+
+	alive := make(map[int32]bool, len(pids))
+	procs := make(map[int32]procFDs, len(pids))
+	changed := make(map[int32]bool)
+	var rescan, unchanged []int32
+	for _, pid := range pids {
+		alive[pid] = true
+		cur := c.procState(pid)
+		procs[pid] = cur
+		if prev, seen := c.procs[pid]; !seen || cur.count <= 0 || cur != prev || c.denied[pid] {
+			changed[pid] = true
+			rescan = append(rescan, pid)
+		} else {
+			unchanged = append(unchanged, pid)
+		}
+	}
+
+	// Carry over owners that neither exited nor are about to be rescanned.
+	result := make(map[uint64][]domain.SocketOwner, len(inodes))
+	var fresh []uint64
+	for _, ino := range inodes {
+		prev, known := c.owners[ino]
+		if !known {
+			fresh = append(fresh, ino)
+		}
+		for _, o := range prev {
+			if alive[o.PID] && !changed[o.PID] {
+				result[ino] = append(result[ino], o)
+			}
+		}
+	}
+
+	s := newFDScanner()
+	s.procRoot = c.procRoot
+	var denied []int32
+	if len(rescan) > 0 {
+		var found map[uint64][]domain.SocketOwner
+		s.pids = rescan
+		found, denied = s.scan(inodes)
+		mergeOwners(result, found)
+	}
+
+	var orphans []uint64
+	for _, ino := range fresh {
+		if len(result[ino]) == 0 {
+			orphans = append(orphans, ino)
+		}
+	}
+	if len(orphans) > 0 && len(unchanged) > 0 {
+		s.pids = unchanged
+		found, _ := s.scan(orphans)
+		// A process holding a socket we had not seen did change its
+		// descriptors, so what was carried over for it may be stale: an
+		// inode it closed can have been reused by another socket since.
+		stale := make(map[int32]bool)
+		for _, owners := range found {
+			for _, o := range owners {
+				stale[o.PID] = true
+			}
+		}
+		if len(stale) > 0 {
+			s.pids = s.pids[:0:0]
+			for pid := range stale {
+				changed[pid] = true
+				s.pids = append(s.pids, pid)
+			}
+			for ino, owners := range result {
+				result[ino] = slices.DeleteFunc(owners, func(o domain.SocketOwner) bool { return stale[o.PID] })
+			}
+			found, _ = s.scan(inodes)
+			mergeOwners(result, found)
+		}
+	}
+
+	if !commit {
+		// Processes denied before were rescanned, so denied covers them.
+		return result, len(denied)
+	}
+	c.owners = make(map[uint64][]domain.SocketOwner, len(inodes))
+	for _, ino := range inodes {
+		c.owners[ino] = result[ino]
+	}
+	c.procs = procs
+	for pid := range c.denied {
+		if !alive[pid] || changed[pid] {
+			delete(c.denied, pid)
+		}
+	}
+	for _, pid := range denied {
+		c.denied[pid] = true
+	}
+
+	// Callers fill in Owners[i].Process, so hand out copies.
+	out := make(map[uint64][]domain.SocketOwner, len(result))
+	for ino, owners := range result {
+		out[ino] = append([]domain.SocketOwner(nil), owners...)
+	}
+	return out, len(c.denied)
+}
+
+// procState reads what identifies pid's descriptor table between lookups.
+// The size of /proc/<pid>/fd is its number of open descriptors on Linux 6.2
+// and later and 0 before.
+func (c *ownerCache) procState(pid int32) procFDs {
+	dir := c.procRoot + "/" + strconv.Itoa(int(pid))
+	return procFDs{start: procStartTime(dir), count: c.fdDirSize(dir + "/fd")}
+}
+
+func statSize(path string) int64 {
+	var st unix.Stat_t
+	if unix.Stat(path, &st) != nil {
+		return -1
+	}
+	return st.Size
+}
+
+// procStartTime returns field 22 of <dir>/stat, the process start time, or
+// 0 when it cannot be read. The fields are counted after the parenthesised
+// command name, which may itself contain spaces.
+func procStartTime(dir string) uint64 {
+	data, err := os.ReadFile(dir + "/stat")
+	if err != nil {
+		return 0
+	}
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 20 {
+		return 0
+	}
+	start, _ := strconv.ParseUint(fields[19], 10, 64) // fields[0] is field 3
+	return start
+}
+
+func mergeOwners(dst, src map[uint64][]domain.SocketOwner) {
+	for ino, owners := range src {
+		merged := append(dst[ino], owners...)
+		sort.Slice(merged, func(i, j int) bool { return merged[i].PID < merged[j].PID })
+		dst[ino] = merged
+	}
+}
@@ -0,0 +1,136 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+const (
+	// Process events connector (linux/connector.h, linux/cn_proc.h).
+	cnIdxProc         = 1
+	cnValProc         = 1
+	procCnMcastListen = 1
+	cnMsgLen          = 20 // struct cn_msg without data
+
+	procEventExec = 0x00000002
+	procEventExit = 0x80000000
+
+	// proc_event: what, cpu, timestamp_ns, then the event's pid and tgid.
+	procEventDataOff = 16
+	procEventMinLen  = procEventDataOff + 8
+)
+
+// ProcMonitor implements ports.ProcessMonitor with the kernel's process
+// events connector. Subscribing needs CAP_NET_ADMIN, and the kernel only
+// delivers events to listeners in the initial PID and user namespaces.
+type ProcMonitor struct{}
+
+// NewProcMonitor creates a ProcMonitor.
+func NewProcMonitor() *ProcMonitor { return &ProcMonitor{} }
+
+// Events subscribes to exec and exit events. Events lost to an overflowing
+// socket buffer are not reported; callers' TTLs cover for them.
+func (m *ProcMonitor) Events(ctx context.Context) (<-chan ports.ProcessEvent, error) {
+	c, err := dialNetlink(unix.NETLINK_CONNECTOR)
+	if err != nil {
+		if err == unix.EPROTONOSUPPORT {
+			return nil, fmt.Errorf("%w: kernel built without the process events connector", domain.ErrUnsupported)
+		}
+		return nil, err
+	}
+	if err := unix.Bind(c.fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: cnIdxProc}); err != nil {
+		c.close()
+		if err == unix.EPERM || err == unix.EACCES {
+			return nil, fmt.Errorf("%w: process events need CAP_NET_ADMIN", domain.ErrPermissionDenied)
+		}
+		return nil, fmt.Errorf("bind process events connector: %w", err)
+	}
+	if err := c.send(procListenReq()); err != nil {
+		c.close()
+		return nil, fmt.Errorf("subscribe to process events: %w", err)
+	}
+
+	out := make(chan ports.ProcessEvent, 256)
+	go readProcEvents(ctx, c, out)
+	return out, nil
+}
+
+// procListenReq builds the PROC_CN_MCAST_LISTEN request that turns event
+// delivery on.
+func procListenReq() []byte {
+	l := nlmsgHdrLen + cnMsgLen + 4
+	buf := make([]byte, l)
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(l))
+	binary.LittleEndian.PutUint16(buf[4:6], syscall.NLMSG_DONE)
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(os.Getpid()))
+	cn := buf[nlmsgHdrLen:]
+	binary.LittleEndian.PutUint32(cn[0:4], cnIdxProc)
+	binary.LittleEndian.PutUint32(cn[4:8], cnValProc)
+	binary.LittleEndian.PutUint16(cn[16:18], 4)
+	binary.LittleEndian.PutUint32(cn[cnMsgLen:], procCnMcastListen)
+	return buf
+}
+
+// readProcEvents forwards exec and exit events read from conn until ctx is
+// done.
+func readProcEvents(ctx context.Context, conn nlConn, out chan<- ports.ProcessEvent) {
+	defer close(out)
+	defer conn.close()
+	buf := make([]byte, netlinkRecvBufLen)
+	for ctx.Err() == nil {
+		n, err := conn.recv(buf)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR || err == unix.ENOBUFS {
+				continue
+			}
+			return
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:min(n, len(buf))])
+		if err != nil {
+			continue
+		}
+		for _, msg := range msgs {
+			ev, ok := parseProcEvent(msg.Data)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// parseProcEvent decodes a cn_msg carrying a proc_event. Exits of threads
+// other than a process's main thread are dropped.
+func parseProcEvent(data []byte) (ports.ProcessEvent, bool) {
+	if len(data) < cnMsgLen+procEventMinLen ||
+		binary.LittleEndian.Uint32(data[0:4]) != cnIdxProc || binary.LittleEndian.Uint32(data[4:8]) != cnValProc {
+		return ports.ProcessEvent{}, false
+	}
+	ev := data[cnMsgLen:]
+	pid := int32(binary.LittleEndian.Uint32(ev[procEventDataOff:]))
+	tgid := int32(binary.LittleEndian.Uint32(ev[procEventDataOff+4:]))
+	switch binary.LittleEndian.Uint32(ev[0:4]) {
+	case procEventExec:
+		return ports.ProcessEvent{Type: ports.ProcessExec, PID: tgid}, true
+	case procEventExit:
+		if pid != tgid {
+			return ports.ProcessEvent{}, false
+		}
+		return ports.ProcessEvent{Type: ports.ProcessExit, PID: tgid}, true
+	}
+	return ports.ProcessEvent{}, false
+}
@@ -0,0 +1,87 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+func procEventMsg(what uint32, pid, tgid int32) []byte {
+	b := make([]byte, cnMsgLen+procEventMinLen+16)
+	binary.LittleEndian.PutUint32(b[0:4], cnIdxProc)
+	binary.LittleEndian.PutUint32(b[4:8], cnValProc)
+	ev := b[cnMsgLen:]
+	binary.LittleEndian.PutUint32(ev[0:4], what)
+	binary.LittleEndian.PutUint32(ev[procEventDataOff:], uint32(pid))
+	binary.LittleEndian.PutUint32(ev[procEventDataOff+4:], uint32(tgid))
+	return b
+}
+
+func TestParseProcEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want ports.ProcessEvent
+		ok   bool
+	}{
+		{"exec", procEventMsg(procEventExec, 42, 42), ports.ProcessEvent{Type: ports.ProcessExec, PID: 42}, true},
+		{"exec by thread", procEventMsg(procEventExec, 43, 42), ports.ProcessEvent{Type: ports.ProcessExec, PID: 42}, true},
+		{"exit", procEventMsg(procEventExit, 42, 42), ports.ProcessEvent{Type: ports.ProcessExit, PID: 42}, true},
+		{"thread exit", procEventMsg(procEventExit, 43, 42), ports.ProcessEvent{}, false},
+		{"fork", procEventMsg(0x1, 42, 42), ports.ProcessEvent{}, false},
+		{"short", procEventMsg(procEventExit, 42, 42)[:cnMsgLen+8], ports.ProcessEvent{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseProcEvent(tt.data)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("parseProcEvent = %+v, %v; want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestProcMonitor_ReportsChildExecAndExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := NewProcMonitor().Events(ctx)
+	if errors.Is(err, domain.ErrPermissionDenied) || errors.Is(err, domain.ErrUnsupported) {
+		t.Skipf("process events unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("true")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := int32(cmd.Process.Pid)
+	cmd.Wait()
+
+	var seen []ports.ProcessEventType
+	timeout := time.After(2 * time.Second)
+	for len(seen) < 2 {
+		select {
+		case ev := <-events:
+			if ev.PID == pid {
+				seen = append(seen, ev.Type)
+			}
+		case <-timeout:
+			// Outside the initial PID namespace the kernel accepts the
+			// subscription but delivers nothing.
+			t.Skipf("saw %v for PID %d; process events not delivered here", seen, pid)
+		}
+	}
+	if seen[0] != ports.ProcessExec || seen[1] != ports.ProcessExit {
+		t.Errorf("events for PID %d = %v, want exec then exit", pid, seen)
+	}
+}
@@ -0,0 +1,228 @@
+//go:build linux
+
+package linux
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+const (
+	inetDiagLocals = 12 // INET_DIAG_LOCALS: array of sockaddr_storage
+	inetDiagPeers  = 13 // INET_DIAG_PEERS
+
+	sockaddrStorageLen = 128
+)
+
+var sctpSources = []inetSource{
+	{domain.SCTP, domain.FamilyIPv4, unix.IPPROTO_SCTP, unix.AF_INET, "sctp", "SCTP", false},
+	{domain.SCTP, domain.FamilyIPv6, unix.IPPROTO_SCTP, unix.AF_INET6, "sctp", "SCTP6", false},
+}
+
+// sctpStates maps enum sctp_state (include/net/sctp/constants.h), which
+// sock_diag and /proc/net/sctp/assocs report for associations.
+var sctpStates = [...]domain.SocketState{
+	0: domain.StateClosed,      // CLOSED
+	1: domain.StateSynSent,     // COOKIE_WAIT
+	2: domain.StateSynSent,     // COOKIE_ECHOED
+	3: domain.StateEstablished, // ESTABLISHED
+	4: domain.StateFinWait1,    // SHUTDOWN_PENDING
+	5: domain.StateFinWait1,    // SHUTDOWN_SENT
+	6: domain.StateCloseWait,   // SHUTDOWN_RECEIVED
+	7: domain.StateLastAck,     // SHUTDOWN_ACK_SENT
+}
+
+func mapSCTPState(st uint8) domain.SocketState {
+	if int(st) < len(sctpStates) {
+		return sctpStates[st]
+	}
+	return domain.StateUnknown
+}
+
+// wantSCTP reports whether SCTP was explicitly requested. It is opt-in
+// because dumping it makes the kernel load the sctp module on demand.
+func wantSCTP(filter *domain.Filter) bool {
+	return filter != nil && hasProto(filter.Protocols, domain.SCTP)
+}
+
+// enumSCTPNetlink dumps SCTP endpoints and associations via sock_diag.
+// sctp_diag honours neither state bitmasks beyond LISTEN nor bytecode, so
+// everything is requested and filtered afterwards.
+func enumSCTPNetlink(ctx context.Context, filter *domain.Filter) ([]sockEntry, error) {
+	var entries []sockEntry
+	for _, src := range sctpSources {
+		if !wantSource(filter, src) {
+			continue
+		}
+		start := len(entries)
+		reset := func() { entries = entries[:start] }
+		err := netlinkDump(ctx, buildInetDiagReq(src, 0, allStates, nil), reset, func(data []byte) {
+			if len(data) >= inetDiagMsgLen {
+				b, ino := parseSCTPDiagMsg(data)
+				entries = append(entries, sockEntry{binding: b, inode: ino})
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// parseSCTPDiagMsg parses an inet_diag_msg for an SCTP endpoint or
+// association. Associations carry their peers and report the association
+// state rather than the socket's.
+func parseSCTPDiagMsg(data []byte) (domain.PortBinding, uint64) {
+	b, ino := parseInetDiagMsg(data, domain.SCTP)
+	attrs := parseAttrs(data[inetDiagMsgLen:])
+	if locals := parseSockaddrs(attrs[inetDiagLocals]); len(locals) > 0 {
+		b.LocalIPs = locals
+	}
+	if _, isAssoc := attrs[inetDiagPeers]; isAssoc || b.RemotePort != 0 {
+		b.State = mapSCTPState(data[1])
+	}
+	return b, ino
+}
+
+// parseSockaddrs decodes the sockaddr_storage array of INET_DIAG_LOCALS.
+func parseSockaddrs(b []byte) []net.IP {
+	var ips []net.IP
+	for ; len(b) >= sockaddrStorageLen; b = b[sockaddrStorageLen:] {
+		switch binary.LittleEndian.Uint16(b[0:2]) {
+		case unix.AF_INET:
+			ips = append(ips, net.IP(append([]byte(nil), b[4:8]...)))
+		case unix.AF_INET6:
+			ips = append(ips, domain.NormalizeIP(net.IP(append([]byte(nil), b[8:24]...))))
+		}
+	}
+	return ips
+}
+
+// parseProcSCTP reads /proc/net/sctp/eps and /proc/net/sctp/assocs from dir.
+func parseProcSCTP(dir string) ([]sockEntry, error) {
+	eps, err := parseProcSCTPFile(filepath.Join(dir, "eps"), parseSCTPEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	assocs, err := parseProcSCTPFile(filepath.Join(dir, "assocs"), parseSCTPAssoc)
+	if err != nil {
+		return nil, err
+	}
+	return append(eps, assocs...), nil
+}
+
+func parseProcSCTPFile(path string, parse func(fields []string) (sockEntry, bool)) ([]sockEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []sockEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // skip header
+	for scanner.Scan() {
+		if e, ok := parse(strings.Fields(scanner.Text())); ok {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseSCTPEndpoint parses an eps row:
+// ENDPT SOCK STY SST HBKT LPORT UID INODE LADDRS...
+func parseSCTPEndpoint(fields []string) (sockEntry, bool) {
+	if len(fields) < 9 {
+		return sockEntry{}, false
+	}
+	sst, _ := strconv.ParseUint(fields[3], 10, 8)
+	port, err := strconv.ParseUint(fields[5], 10, 16)
+	if err != nil {
+		return sockEntry{}, false
+	}
+	uid, _ := strconv.ParseUint(fields[6], 10, 32)
+	inode, _ := strconv.ParseUint(fields[7], 10, 64)
+	locals := parseSCTPAddrs(fields[8:])
+	if len(locals) == 0 {
+		return sockEntry{}, false
+	}
+	b := newBinding(domain.SCTP, locals[0], uint16(port), nil, 0, uint8(sst))
+	b.LocalIPs = locals
+	b.UID, b.HasUID = uint32(uid), true
+	return sockEntry{binding: b, inode: inode}, true
+}
+
+// parseSCTPAssoc parses an assocs row:
+// ASSOC SOCK STY SST ST HBKT ASSOC-ID TX_QUEUE RX_QUEUE UID INODE LPORT RPORT
+// LADDRS <-> RADDRS HBINT INS OUTS ...
+// The primary address on each side is marked with a leading '*'.
+func parseSCTPAssoc(fields []string) (sockEntry, bool) {
+	if len(fields) < 15 {
+		return sockEntry{}, false
+	}
+	st, _ := strconv.ParseUint(fields[4], 10, 8)
+	uid, _ := strconv.ParseUint(fields[9], 10, 32)
+	inode, _ := strconv.ParseUint(fields[10], 10, 64)
+	lport, err1 := strconv.ParseUint(fields[11], 10, 16)
+	rport, err2 := strconv.ParseUint(fields[12], 10, 16)
+	if err1 != nil || err2 != nil {
+		return sockEntry{}, false
+	}
+	sep := -1
+	for i := 13; i < len(fields); i++ {
+		if fields[i] == "<->" {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return sockEntry{}, false
+	}
+	locals := parseSCTPAddrs(fields[13:sep])
+	remotes := parseSCTPAddrs(fields[sep+1:])
+	if len(locals) == 0 {
+		return sockEntry{}, false
+	}
+	var remote net.IP
+	if len(remotes) > 0 {
+		remote = remotes[0]
+	}
+	b := newBinding(domain.SCTP, locals[0], uint16(lport), remote, uint16(rport), 0)
+	b.State = mapSCTPState(uint8(st))
+	b.LocalIPs = locals
+	b.UID, b.HasUID = uint32(uid), true
+	return sockEntry{binding: b, inode: inode}, true
+}
+
+// parseSCTPAddrs parses a run of address fields, stopping at the first
+// non-address, and moves the '*'-marked primary address to the front.
+func parseSCTPAddrs(fields []string) []net.IP {
+	var ips []net.IP
+	for _, f := range fields {
+		primary := strings.HasPrefix(f, "*")
+		ip := net.ParseIP(strings.TrimPrefix(f, "*"))
+		if ip == nil {
+			break
+		}
+		ip = domain.NormalizeIP(ip)
+		if primary {
+			ips = append([]net.IP{ip}, ips...)
+		} else {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
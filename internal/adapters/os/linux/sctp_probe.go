@@ -0,0 +1,70 @@
+//go:build linux
+
+package linux
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// SCTPProber bind-checks SCTP ports, which the net package cannot open.
+type SCTPProber struct{}
+
+// NewSCTPProber returns a bind checker for SCTP ports.
+func NewSCTPProber() SCTPProber { return SCTPProber{} }
+
+// Available reports whether the kernel can create SCTP sockets; the sctp
+// module is often blacklisted or not built.
+func (SCTPProber) Available() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_STREAM, unix.IPPROTO_SCTP)
+	if err != nil {
+		return fmt.Errorf("%w: SCTP is not available in this kernel: %v", domain.ErrUnsupported, err)
+	}
+	unix.Close(fd)
+	return nil
+}
+
+// PortFree bind-checks port on addr or, when addr is nil, on the wildcard
+// address, dual-stack when IPv6 is available like net.Listen does for TCP.
+func (SCTPProber) PortFree(addr *net.IPAddr, port uint16) bool {
+	if addr != nil {
+		family, sa := sctpSockaddr(addr, port)
+		fd, err := unix.Socket(family, unix.SOCK_STREAM, unix.IPPROTO_SCTP)
+		if err != nil {
+			return false
+		}
+		defer unix.Close(fd)
+		return unix.Bind(fd, sa) == nil
+	}
+	fd, err := unix.Socket(unix.AF_INET6, unix.SOCK_STREAM, unix.IPPROTO_SCTP)
+	var sa unix.Sockaddr = &unix.SockaddrInet6{Port: int(port)}
+	if err != nil {
+		fd, err = unix.Socket(unix.AF_INET, unix.SOCK_STREAM, unix.IPPROTO_SCTP)
+		sa = &unix.SockaddrInet4{Port: int(port)}
+		if err != nil {
+			return false
+		}
+	}
+	defer unix.Close(fd)
+	return unix.Bind(fd, sa) == nil
+}
+
+func sctpSockaddr(addr *net.IPAddr, port uint16) (int, unix.Sockaddr) {
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		sa := &unix.SockaddrInet4{Port: int(port)}
+		copy(sa.Addr[:], ip4)
+		return unix.AF_INET, sa
+	}
+	sa := &unix.SockaddrInet6{Port: int(port)}
+	copy(sa.Addr[:], addr.IP.To16())
+	if addr.Zone != "" {
+		if ifi, err := net.InterfaceByName(addr.Zone); err == nil {
+			sa.ZoneId = uint32(ifi.Index)
+		}
+	}
+	return unix.AF_INET6, sa
+}
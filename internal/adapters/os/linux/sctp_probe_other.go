@@ -0,0 +1,22 @@
+//go:build !linux
+
+package linux
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// SCTPProber reports SCTP as unsupported outside Linux.
+type SCTPProber struct{}
+
+// NewSCTPProber returns a prober whose Available always fails.
+func NewSCTPProber() SCTPProber { return SCTPProber{} }
+
+func (SCTPProber) Available() error {
+	return fmt.Errorf("%w: SCTP is only supported on Linux", domain.ErrUnsupported)
+}
+
+func (SCTPProber) PortFree(*net.IPAddr, uint16) bool { return false }
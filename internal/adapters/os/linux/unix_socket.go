@@ -0,0 +1,169 @@
+//go:build linux
+
+package linux
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+const (
+	udiagShowName = 0x00000001
+	udiagShowUID  = 0x00000040 // Linux 5.3+; ignored by older kernels
+	unixDiagName  = 0
+	unixDiagUID   = 7
+
+	// __SO_ACCEPTCON in the /proc/net/unix Flags column marks a listener.
+	procUnixAcceptCon = 0x00010000
+	procUnixConnected = 3 // SS_CONNECTED
+
+	// sk_state values unix_diag reports for Unix sockets.
+	tcpEstablished = 0x01
+	tcpClose       = 0x07
+	tcpListen      = 0x0A
+)
+
+// wantUnix reports whether Unix domain sockets were explicitly requested.
+// They are opt-in because a typical host has hundreds of them, and have no
+// port, so any port constraint rules them out.
+func wantUnix(filter *domain.Filter) bool {
+	return filter != nil && hasProto(filter.Protocols, domain.Unix) &&
+		len(filter.Ports) == 0 && filter.PortRange == nil
+}
+
+// enumUnixNetlink dumps bound Unix domain sockets via UNIX_DIAG.
+func enumUnixNetlink(ctx context.Context, filter *domain.Filter) ([]sockEntry, error) {
+	var entries []sockEntry
+	reset := func() { entries = entries[:0] }
+	err := netlinkDump(ctx, buildUnixDiagReq(stateMask(filter)), reset, func(data []byte) {
+		if b, ino, ok := parseUnixDiagMsg(data); ok {
+			entries = append(entries, sockEntry{binding: b, inode: ino})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func buildUnixDiagReq(states uint32) []byte {
+	// unix_diag_req: family, protocol, pad[2], states, ino, show, cookie[2]
+	const reqLen = 24
+	buf := newDiagReq(reqLen)
+	buf[nlmsgHdrLen] = unix.AF_UNIX
+	binary.LittleEndian.PutUint32(buf[nlmsgHdrLen+4:nlmsgHdrLen+8], states)
+	binary.LittleEndian.PutUint32(buf[nlmsgHdrLen+12:nlmsgHdrLen+16], udiagShowName|udiagShowUID)
+	return buf
+}
+
+func parseUnixDiagMsg(data []byte) (domain.PortBinding, uint64, bool) {
+	// unix_diag_msg layout:
+	// [0] family, [1] type, [2] state, [3] pad
+	// [4-7] inode, [8-15] cookie, followed by rtattrs
+	const msgLen = 16
+	if len(data) < msgLen {
+		return domain.PortBinding{}, 0, false
+	}
+	attrs := parseAttrs(data[msgLen:])
+	name, ok := attrs[unixDiagName]
+	if !ok || len(name) == 0 {
+		return domain.PortBinding{}, 0, false
+	}
+	inode := binary.LittleEndian.Uint32(data[4:8])
+	b := domain.PortBinding{
+		Protocol: domain.Unix,
+		Family:   domain.FamilyUnix,
+		Path:     unixPath(name),
+		SockType: mapUnixType(uint16(data[1])),
+		State:    mapLinuxState(data[2]),
+	}
+	if uid := attrs[unixDiagUID]; len(uid) >= 4 {
+		b.UID, b.HasUID = binary.LittleEndian.Uint32(uid), true
+	}
+	return b, uint64(inode), true
+}
+
+// unixPath renders a sun_path, using the "@" convention for abstract names.
+func unixPath(name []byte) string {
+	if name[0] == 0 {
+		return "@" + string(name[1:])
+	}
+	return strings.TrimRight(string(name), "\x00")
+}
+
+func parseProcNetUnix(path string) ([]sockEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []sockEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // skip header
+	for scanner.Scan() {
+		// Num RefCount Protocol Flags Type St Inode [Path]
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue // unnamed socket
+		}
+		flags, _ := strconv.ParseUint(fields[3], 16, 32)
+		typ, _ := strconv.ParseUint(fields[4], 16, 16)
+		st, _ := strconv.ParseUint(fields[5], 16, 8)
+		inode, err := strconv.ParseUint(fields[6], 10, 64)
+		if err != nil {
+			continue // malformed line
+		}
+
+		entries = append(entries, sockEntry{
+			binding: domain.PortBinding{
+				Protocol: domain.Unix,
+				Family:   domain.FamilyUnix,
+				Path:     strings.Join(fields[7:], " "),
+				SockType: mapUnixType(uint16(typ)),
+				State:    mapLinuxState(procUnixState(flags, st)),
+			},
+			inode: inode,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// procUnixState converts the Flags and St (the SS_* socket state) columns
+// of /proc/net/unix into the sk_state unix_diag reports, so both backends
+// agree: listeners are TCP_LISTEN, connected sockets TCP_ESTABLISHED and
+// every other socket TCP_CLOSE.
+func procUnixState(flags, st uint64) uint8 {
+	switch {
+	case flags&procUnixAcceptCon != 0:
+		return tcpListen
+	case st == procUnixConnected:
+		return tcpEstablished
+	default:
+		return tcpClose
+	}
+}
+
+func mapUnixType(t uint16) domain.SocketType {
+	switch t {
+	case unix.SOCK_STREAM:
+		return domain.SockStream
+	case unix.SOCK_DGRAM:
+		return domain.SockDgram
+	case unix.SOCK_SEQPACKET:
+		return domain.SockSeqpacket
+	default:
+		return domain.SockTypeUnknown
+	}
+}
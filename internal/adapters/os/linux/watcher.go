@@ -0,0 +1,330 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+const (
+	// sock_diag multicast groups announcing destroyed sockets (SKNLGRP_*).
+	sknlgrpInetTCPDestroy  = 1
+	sknlgrpInetUDPDestroy  = 2
+	sknlgrpInet6TCPDestroy = 3
+	sknlgrpInet6UDPDestroy = 4
+
+	inetDiagProtocol = 10 // INET_DIAG_PROTOCOL: u8 IP protocol
+
+	// defaultEventPoll is how often Events looks for new sockets. A dump
+	// of the socket tables without the owner scan takes well under a
+	// millisecond, so this can be much shorter than a watch refresh.
+	defaultEventPoll = 100 * time.Millisecond
+)
+
+// Watcher implements ports.Watcher. Sockets are reported gone as soon as the
+// kernel announces their destruction on the sock_diag destroy groups, which
+// catches listeners that live for less than a poll interval too. New
+// sockets have no such notification and are found by polling.
+type Watcher struct {
+	enum *Enumerator
+	poll time.Duration
+}
+
+// NewWatcher creates a Watcher listing sockets with e.
+func NewWatcher(e *Enumerator) *Watcher {
+	return &Watcher{enum: e, poll: defaultEventPoll}
+}
+
+func (w *Watcher) Snapshot(ctx context.Context, filter *domain.Filter) (*ports.Snapshot, error) {
+	result, err := w.enum.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.Snapshot{Bindings: result.Data, Partial: result.Partial}, nil
+}
+
+// Events subscribes to the destroy groups matching filter, which needs
+// CAP_NET_ADMIN, and starts polling. Only TCP and UDP sockets in porthog's
+// own network namespace are covered.
+func (w *Watcher) Events(ctx context.Context, filter *domain.Filter) (<-chan ports.WatchEvent, error) {
+	e := w.enum
+	if e.foreignRoot() || !e.netlinkEnabled() || e.opts.Netns != "" || e.opts.AllNamespaces {
+		return nil, fmt.Errorf("%w: socket events need sock_diag in porthog's own network namespace", domain.ErrUnsupported)
+	}
+	groups := destroyGroups(filter)
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("%w: socket events only cover TCP and UDP", domain.ErrUnsupported)
+	}
+	conn, err := dialDestroyEvents(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	initial := slices.Collect(maps.Values(w.scan(ctx, filter)))
+	known := make(map[sockKey]watched, len(initial))
+	for _, s := range w.attribute(initial, filter) {
+		known[s.key] = s.watched
+	}
+
+	destroyed := make(chan sockEntry, 64)
+	out := make(chan ports.WatchEvent, 64)
+	go readDestroyed(ctx, conn, destroyed)
+	go w.run(ctx, filter, known, destroyed, out)
+	return out, nil
+}
+
+// watched is a socket Events knows about. Sockets the filter rejects once
+// their owners are known are remembered too, so they are not looked up on
+// every poll, but never reported.
+type watched struct {
+	binding domain.PortBinding
+	shown   bool
+}
+
+// run owns the set of known sockets and turns polls and destroy
+// notifications into events.
+func (w *Watcher) run(ctx context.Context, filter *domain.Filter, known map[sockKey]watched, destroyed <-chan sockEntry, out chan<- ports.WatchEvent) {
+	defer close(out)
+	emit := func(typ ports.WatchEventType, b domain.PortBinding) bool {
+		select {
+		case out <- ports.WatchEvent{Type: typ, Binding: b, At: time.Now()}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// A socket destroyed before any poll saw it is still reported, as far
+	// as the filter can tell without its owner. Its state before closing is
+	// unknown too, so with a state criterion it is not: `--state listen`
+	// would otherwise report every client connection closing on the host.
+	reportUnseen := filter == nil || len(filter.States) == 0
+	var unseen *domain.Filter
+	if filter != nil {
+		f := *filter
+		f.PIDs = nil
+		unseen = &f
+	}
+
+	ticker := time.NewTicker(w.poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case se, ok := <-destroyed:
+			if !ok {
+				return
+			}
+			k := keyOf(se)
+			if s, seen := known[k]; seen {
+				delete(known, k)
+				if s.shown && !emit(ports.WatchRemoved, s.binding) {
+					return
+				}
+			} else if reportUnseen && unseen.Matches(&se.binding) && !emit(ports.WatchRemoved, se.binding) {
+				return
+			}
+		case <-ticker.C:
+			curr := w.scan(ctx, filter)
+			if ctx.Err() != nil {
+				return
+			}
+			for k, s := range known {
+				if _, ok := curr[k]; !ok {
+					delete(known, k)
+					if s.shown && !emit(ports.WatchRemoved, s.binding) {
+						return
+					}
+				}
+			}
+			var added []sockEntry
+			for k, se := range curr {
+				if _, ok := known[k]; !ok {
+					added = append(added, se)
+				}
+			}
+			for _, s := range w.attribute(added, filter) {
+				known[s.key] = s.watched
+				if s.shown && !emit(ports.WatchAdded, s.binding) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// scan dumps the socket tables without looking up owners.
+func (w *Watcher) scan(ctx context.Context, filter *domain.Filter) map[sockKey]sockEntry {
+	entries, _ := w.enum.collect(ctx, filter, "/proc/net", true)
+	entries = prefilter(entries, filter)
+	m := make(map[sockKey]sockEntry, len(entries))
+	for _, se := range entries {
+		m[keyOf(se)] = se
+	}
+	return m
+}
+
+type attributed struct {
+	key sockKey
+	watched
+}
+
+// attribute looks up the owners of new sockets, through the enumerator's
+// owner cache when it has one so that polls only rescan processes whose
+// descriptors changed, and matches them against filter.
+func (w *Watcher) attribute(entries []sockEntry, filter *domain.Filter) []attributed {
+	if len(entries) == 0 {
+		return nil
+	}
+	inodes := make([]uint64, len(entries))
+	for i, se := range entries {
+		inodes[i] = se.inode
+	}
+	var owners map[uint64][]domain.SocketOwner
+	var denied int
+	if w.enum.owners != nil {
+		owners, denied = w.enum.owners.peek(inodes)
+	} else {
+		owners, denied = mapInodesToOwners(w.enum.procRoot, inodes, nil)
+	}
+	restricted := w.enum.restricted(denied)
+	out := make([]attributed, len(entries))
+	for i, se := range entries {
+		b := w.enum.attribute(se, owners[se.inode], restricted)
+		out[i] = attributed{key: keyOf(se), watched: watched{binding: b, shown: filter.Matches(&b)}}
+	}
+	return out
+}
+
+// sockKey identifies a socket across dumps and destroy notifications: by its
+// cookie where sock_diag reported one, otherwise by its addresses and inode.
+type sockKey struct {
+	proto         domain.Protocol
+	cookie        uint64
+	inode         uint64
+	local, remote string
+}
+
+func keyOf(se sockEntry) sockKey {
+	b := &se.binding
+	if se.cookie != 0 {
+		return sockKey{proto: b.Protocol, cookie: se.cookie}
+	}
+	return sockKey{
+		proto: b.Protocol, inode: se.inode, local: b.LocalAddr(),
+		remote: net.JoinHostPort(b.RemoteIP.String(), fmt.Sprint(b.RemotePort)),
+	}
+}
+
+// destroyGroups returns the destroy groups covering the protocols and
+// families filter selects.
+func destroyGroups(filter *domain.Filter) []int {
+	var groups []int
+	for _, src := range inetSources[:4] { // TCP, TCP6, UDP, UDP6
+		if !wantSource(filter, src) {
+			continue
+		}
+		switch {
+		case src.proto == domain.TCP && src.family == domain.FamilyIPv4:
+			groups = append(groups, sknlgrpInetTCPDestroy)
+		case src.proto == domain.TCP:
+			groups = append(groups, sknlgrpInet6TCPDestroy)
+		case src.family == domain.FamilyIPv4:
+			groups = append(groups, sknlgrpInetUDPDestroy)
+		default:
+			groups = append(groups, sknlgrpInet6UDPDestroy)
+		}
+	}
+	return groups
+}
+
+// dialDestroyEvents opens a sock_diag socket subscribed to groups. Joining
+// them needs CAP_NET_ADMIN.
+func dialDestroyEvents(groups []int) (*sockDiagConn, error) {
+	c, err := dialNetlink(unix.NETLINK_SOCK_DIAG)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Bind(c.fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		c.close()
+		return nil, err
+	}
+	for _, g := range groups {
+		if err := unix.SetsockoptInt(c.fd, unix.SOL_NETLINK, unix.NETLINK_ADD_MEMBERSHIP, g); err != nil {
+			c.close()
+			if err == unix.EPERM || err == unix.EACCES {
+				return nil, fmt.Errorf("%w: socket destroy events need CAP_NET_ADMIN", domain.ErrPermissionDenied)
+			}
+			return nil, fmt.Errorf("join sock_diag group %d: %w", g, err)
+		}
+	}
+	return c, nil
+}
+
+// readDestroyed forwards the sockets announced on conn until ctx is done.
+// Notifications dropped because the socket buffer overflowed (ENOBUFS) are
+// made up for by the next poll.
+func readDestroyed(ctx context.Context, conn nlConn, out chan<- sockEntry) {
+	defer close(out)
+	defer conn.close()
+	buf := make([]byte, netlinkRecvBufLen)
+	for ctx.Err() == nil {
+		n, err := conn.recv(buf)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR || err == unix.ENOBUFS {
+				continue
+			}
+			return
+		}
+		msgs, err := syscall.ParseNetlinkMessage(buf[:min(n, len(buf))])
+		if err != nil {
+			continue
+		}
+		for _, msg := range msgs {
+			se, ok := parseDestroyed(msg)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- se:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// parseDestroyed parses a destroy notification: an inet_diag_msg followed
+// by attributes naming the IP protocol.
+func parseDestroyed(msg syscall.NetlinkMessage) (sockEntry, bool) {
+	if msg.Header.Type != sockDiagByFamily || len(msg.Data) < inetDiagMsgLen {
+		return sockEntry{}, false
+	}
+	attr := parseAttrs(msg.Data[inetDiagMsgLen:])[inetDiagProtocol]
+	if len(attr) < 1 {
+		return sockEntry{}, false
+	}
+	var proto domain.Protocol
+	switch attr[0] {
+	case unix.IPPROTO_TCP:
+		proto = domain.TCP
+	case unix.IPPROTO_UDP:
+		proto = domain.UDP
+	default:
+		return sockEntry{}, false
+	}
+	b, ino := parseInetDiagMsg(msg.Data, proto)
+	return sockEntry{binding: b, inode: ino, cookie: diagCookie(msg.Data)}, true
+}
@@ -0,0 +1,146 @@
+//go:build linux
+
+package linux
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+// subscribe starts a TCP watcher, skipping the test where destroy events are
+// not available to the test process.
+func subscribe(t *testing.T) <-chan ports.WatchEvent {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	events, err := NewWatcher(NewEnumerator()).Events(ctx, &domain.Filter{Protocols: []domain.Protocol{domain.TCP}})
+	if errors.Is(err, domain.ErrPermissionDenied) || errors.Is(err, domain.ErrUnsupported) {
+		t.Skipf("socket events unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+// nextEvent waits for an event of typ on port, ignoring other sockets.
+func nextEvent(t *testing.T, events <-chan ports.WatchEvent, typ ports.WatchEventType, port uint16) ports.WatchEvent {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				t.Fatal("event channel closed")
+			}
+			if ev.Type == typ && ev.Binding.LocalPort == port {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("no event %d for port %d", typ, port)
+		}
+	}
+}
+
+func listenPort(t *testing.T) (net.Listener, uint16) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln, uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func TestWatcher_ReportsListenerLifecycle(t *testing.T) {
+	events := subscribe(t)
+	ln, port := listenPort(t)
+
+	added := nextEvent(t, events, ports.WatchAdded, port)
+	if added.Binding.PID != int32(os.Getpid()) {
+		t.Errorf("added PID = %d, want %d", added.Binding.PID, os.Getpid())
+	}
+	ln.Close()
+	removed := nextEvent(t, events, ports.WatchRemoved, port)
+	if removed.Binding.PID != int32(os.Getpid()) {
+		t.Errorf("removed PID = %d, want the owner seen when it was added", removed.Binding.PID)
+	}
+}
+
+func TestWatcher_ReportsListenerClosedBeforePoll(t *testing.T) {
+	events := subscribe(t)
+	ln, port := listenPort(t)
+	ln.Close()
+
+	// Closed well within a poll interval, so only the destroy notification
+	// can have seen it.
+	nextEvent(t, events, ports.WatchRemoved, port)
+}
+
+func TestWatcher_UnseenDestroys(t *testing.T) {
+	// A client connection closing that no poll saw, as the destroy group
+	// reports it.
+	conn := sockEntry{inode: 4242, binding: domain.PortBinding{
+		Protocol: domain.TCP, Family: domain.FamilyIPv4,
+		LocalIP: net.IPv4(127, 0, 0, 1), LocalPort: 41234,
+		RemoteIP: net.IPv4(127, 0, 0, 1), RemotePort: 5432, State: domain.StateClosed,
+	}}
+	tests := []struct {
+		name   string
+		filter *domain.Filter
+		want   bool
+	}{
+		{"no filter", nil, true},
+		{"tcp", &domain.Filter{Protocols: []domain.Protocol{domain.TCP}}, true},
+		{"other port", &domain.Filter{Ports: []uint16{8080}}, false},
+		{"listen", &domain.Filter{States: []domain.SocketState{domain.StateListen}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			w := &Watcher{enum: NewEnumerator(), poll: time.Hour}
+			destroyed := make(chan sockEntry, 1)
+			out := make(chan ports.WatchEvent)
+			destroyed <- conn
+			close(destroyed)
+			go w.run(ctx, tt.filter, map[sockKey]watched{}, destroyed, out)
+
+			var got []ports.WatchEvent
+			for ev := range out {
+				got = append(got, ev)
+			}
+			if reported := len(got) == 1 && got[0].Type == ports.WatchRemoved; reported != tt.want || len(got) > 1 {
+				t.Errorf("got events %v, want removal reported: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDestroyGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *domain.Filter
+		want   []int
+	}{
+		{"all", nil, []int{sknlgrpInetTCPDestroy, sknlgrpInet6TCPDestroy, sknlgrpInetUDPDestroy, sknlgrpInet6UDPDestroy}},
+		{"tcp4", &domain.Filter{Protocols: []domain.Protocol{domain.TCP}, Families: []domain.AddressFamily{domain.FamilyIPv4}}, []int{sknlgrpInetTCPDestroy, sknlgrpInet6TCPDestroy}},
+		{"tcp6", &domain.Filter{Protocols: []domain.Protocol{domain.TCP}, Families: []domain.AddressFamily{domain.FamilyIPv6}}, []int{sknlgrpInet6TCPDestroy}},
+		{"unix", &domain.Filter{Protocols: []domain.Protocol{domain.Unix}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := destroyGroups(tt.filter)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("destroyGroups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -102,7 +102,7 @@ func enumTCP() ([]domain.PortBinding, error) {
 	out := make([]domain.PortBinding, 0, count)
 	for _, r := range rows {
 		out = append(out, domain.PortBinding{
-			Protocol: domain.TCP, LocalIP: u32ToIP(r.LocalAddr), LocalPort: ntohs(r.LocalPort),
+			Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: u32ToIP(r.LocalAddr), LocalPort: ntohs(r.LocalPort),
 			RemoteIP: u32ToIP(r.RemoteAddr), RemotePort: ntohs(r.RemotePort),
 			State: mapTCPState(r.State), PID: int32(r.OwningPID),
 		})
@@ -141,7 +141,7 @@ func enumUDP() ([]domain.PortBinding, error) {
 	out := make([]domain.PortBinding, 0, count)
 	for _, r := range rows {
 		out = append(out, domain.PortBinding{
-			Protocol: domain.UDP, LocalIP: u32ToIP(r.LocalAddr), LocalPort: ntohs(r.LocalPort),
+			Protocol: domain.UDP, Family: domain.FamilyIPv4, LocalIP: u32ToIP(r.LocalAddr), LocalPort: ntohs(r.LocalPort),
 			State: domain.StateListen, PID: int32(r.OwningPID),
 		})
 	}
@@ -162,14 +162,29 @@ func u32ToIP(addr uint32) net.IP {
 	return ip
 }
 
+// mapTCPState maps MIB_TCP_STATE values.
 func mapTCPState(state uint32) domain.SocketState {
 	switch state {
+	case 1:
+		return domain.StateClosed
 	case 2:
 		return domain.StateListen
+	case 3:
+		return domain.StateSynSent
+	case 4:
+		return domain.StateSynRecv
 	case 5:
 		return domain.StateEstablished
+	case 6:
+		return domain.StateFinWait1
+	case 7:
+		return domain.StateFinWait2
 	case 8:
 		return domain.StateCloseWait
+	case 9:
+		return domain.StateClosing
+	case 10:
+		return domain.StateLastAck
 	case 11:
 		return domain.StateTimeWait
 	default:
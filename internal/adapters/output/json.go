@@ -2,6 +2,7 @@ package output
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
@@ -18,19 +19,86 @@ type jsonEnvelope struct {
 
 type jsonBinding struct {
 	Protocol   string       `json:"protocol"`
+	Family     string       `json:"family"`
 	LocalAddr  string       `json:"local_addr"`
 	LocalPort  uint16       `json:"local_port"`
+	LocalAddrs []string     `json:"local_addrs,omitempty"` // multi-homed SCTP endpoints
 	RemoteAddr string       `json:"remote_addr,omitempty"`
 	RemotePort uint16       `json:"remote_port,omitempty"`
+	Path       string       `json:"path,omitempty"`
+	SocketType string       `json:"socket_type,omitempty"`
+	IPProtocol uint8        `json:"ip_protocol,omitempty"` // raw and ICMP sockets
+	Interface  string       `json:"interface,omitempty"`
+	BoundIndex uint32       `json:"ifindex,omitempty"` // bound device or IPv6 scope
 	State      string       `json:"state"`
 	PID        int32        `json:"pid"`
 	Process    *jsonProcess `json:"process,omitempty"`
+	Owners     []jsonOwner  `json:"owners,omitempty"`
+	Metrics    *jsonMetrics `json:"metrics,omitempty"`
+	Netns      *jsonNetns   `json:"netns,omitempty"`
+	SocketUnit string       `json:"socket_unit,omitempty"`
+	Owner      string       `json:"owner,omitempty"` // process, hidden, kernel or none
+	UID        *uint32      `json:"uid,omitempty"`   // socket owner UID
+	User       string       `json:"user,omitempty"`
+}
+
+type jsonNetns struct {
+	Inode   uint64 `json:"inode"`
+	Name    string `json:"name,omitempty"`
+	PID     int32  `json:"pid,omitempty"`
+	Process string `json:"process,omitempty"`
+}
+
+type jsonOwner struct {
+	PID  int32   `json:"pid"`
+	FDs  []int32 `json:"fds,omitempty"`
+	Name string  `json:"name,omitempty"`
+}
+
+type jsonMetrics struct {
+	RecvQueue      uint32  `json:"recv_queue"`
+	SendQueue      uint32  `json:"send_queue"`
+	RTTMs          float64 `json:"rtt_ms,omitempty"`
+	RTTVarMs       float64 `json:"rttvar_ms,omitempty"`
+	Retransmits    uint32  `json:"retransmits,omitempty"`
+	Cwnd           uint32  `json:"cwnd,omitempty"`
+	BytesSent      uint64  `json:"bytes_sent,omitempty"`
+	BytesAcked     uint64  `json:"bytes_acked,omitempty"`
+	BytesReceived  uint64  `json:"bytes_received,omitempty"`
+	LastDataRecvMs int64   `json:"last_data_recv_ms,omitempty"`
 }
 
 type jsonProcess struct {
-	Name     string `json:"name,omitempty"`
-	Exe      string `json:"exe,omitempty"`
-	Username string `json:"username,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Exe       string         `json:"exe,omitempty"`
+	Cmdline   string         `json:"cmdline,omitempty"` // secrets masked
+	App       string         `json:"app,omitempty"`
+	Username  string         `json:"username,omitempty"`
+	PPID      int32          `json:"ppid,omitempty"`
+	Ancestors []jsonAncestor `json:"ancestors,omitempty"` // nearest first
+	Cwd       string         `json:"cwd,omitempty"`
+	Project   string         `json:"project_root,omitempty"`
+	StartTime string         `json:"start_time,omitempty"`
+	UptimeS   int64          `json:"uptime_s,omitempty"`
+	Usage     *jsonUsage     `json:"usage,omitempty"`
+	Container *jsonContainer `json:"container,omitempty"`
+	Unit      string         `json:"systemd_unit,omitempty"`
+}
+
+type jsonUsage struct {
+	CPUPercent *float64 `json:"cpu_percent,omitempty"` // absent until measured
+	RSSBytes   uint64   `json:"rss_bytes,omitempty"`
+	FDs        *int32   `json:"fds,omitempty"`
+}
+
+type jsonAncestor struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name,omitempty"`
+}
+
+type jsonContainer struct {
+	Runtime string `json:"runtime"`
+	ID      string `json:"id"`
 }
 
 func (r *Renderer) renderJSON(result *domain.PartialResult[[]domain.PortBinding], cmd string) error {
@@ -44,11 +112,35 @@ func (r *Renderer) renderJSON(result *domain.PartialResult[[]domain.PortBinding]
 
 	for _, b := range result.Data {
 		jb := jsonBinding{
-			Protocol:  b.Protocol.String(),
-			LocalAddr: b.LocalIP.String(),
-			LocalPort: b.LocalPort,
-			State:     b.State.String(),
-			PID:       b.PID,
+			Protocol:   b.Protocol.String(),
+			Family:     b.Family.String(),
+			LocalPort:  b.LocalPort,
+			State:      b.State.String(),
+			PID:        b.PID,
+			SocketUnit: b.SocketUnit,
+			IPProtocol: b.IPProtocol,
+			Interface:  b.Interface,
+			BoundIndex: b.IfIndex,
+		}
+		if b.OwnerKind != domain.OwnerUnknown {
+			jb.Owner = b.OwnerKind.String()
+		}
+		if b.HasUID {
+			uid := b.UID
+			jb.UID = &uid
+			jb.User = b.Username
+		}
+		if b.LocalIP != nil {
+			jb.LocalAddr = b.LocalIP.String()
+		}
+		if len(b.LocalIPs) > 1 {
+			for _, ip := range b.LocalIPs {
+				jb.LocalAddrs = append(jb.LocalAddrs, ip.String())
+			}
+		}
+		if b.Protocol == domain.Unix {
+			jb.Path = b.Path
+			jb.SocketType = b.SockType.String()
 		}
 		if b.RemoteIP != nil {
 			jb.RemoteAddr = b.RemoteIP.String()
@@ -56,9 +148,51 @@ func (r *Renderer) renderJSON(result *domain.PartialResult[[]domain.PortBinding]
 		}
 		if b.Process != nil {
 			jb.Process = &jsonProcess{
-				Name: b.Process.Name, Exe: b.Process.Exe, Username: b.Process.Username,
+				Name: b.Process.Name, Exe: b.Process.Exe, Cmdline: b.Process.Cmdline,
+				Username: b.Process.Username, PPID: b.Process.PPID, Unit: b.Process.SystemdUnit,
+				Cwd: b.Process.Cwd, Project: b.Process.ProjectRoot, App: b.Process.AppLabel,
+			}
+			for _, a := range b.Process.Ancestors {
+				jb.Process.Ancestors = append(jb.Process.Ancestors, jsonAncestor{PID: a.PID, Name: a.Name})
+			}
+			if start := b.Process.StartTime(); !start.IsZero() {
+				jb.Process.StartTime = start.UTC().Format(time.RFC3339)
+				jb.Process.UptimeS = int64(b.Process.Uptime(time.Now()) / time.Second)
+			}
+			if u := b.Process.Usage; u != nil {
+				jb.Process.Usage = &jsonUsage{RSSBytes: u.RSS}
+				if u.HasCPU() {
+					cpu := math.Round(u.CPUPercent*10) / 10
+					jb.Process.Usage.CPUPercent = &cpu
+				}
+				if u.FDs >= 0 {
+					fds := u.FDs
+					jb.Process.Usage.FDs = &fds
+				}
+			}
+			if b.Process.InContainer() {
+				jb.Process.Container = &jsonContainer{Runtime: b.Process.ContainerRuntime, ID: b.Process.ContainerID}
 			}
 		}
+		for _, o := range b.Owners {
+			jo := jsonOwner{PID: o.PID, FDs: o.FDs}
+			if o.Process != nil {
+				jo.Name = o.Process.Name
+			}
+			jb.Owners = append(jb.Owners, jo)
+		}
+		if m := b.Metrics; m != nil {
+			jb.Metrics = &jsonMetrics{
+				RecvQueue: m.RecvQueue, SendQueue: m.SendQueue,
+				RTTMs: durationMs(m.RTT), RTTVarMs: durationMs(m.RTTVar),
+				Retransmits: m.Retransmits, Cwnd: m.Cwnd,
+				BytesSent: m.BytesSent, BytesAcked: m.BytesAcked, BytesReceived: m.BytesReceived,
+				LastDataRecvMs: m.LastDataRecv.Milliseconds(),
+			}
+		}
+		if ns := b.Netns; ns != nil {
+			jb.Netns = &jsonNetns{Inode: ns.Inode, Name: ns.Name, PID: ns.PID, Process: ns.Process}
+		}
 		env.Data = append(env.Data, jb)
 	}
 
@@ -66,3 +200,7 @@ func (r *Renderer) renderJSON(result *domain.PartialResult[[]domain.PortBinding]
 	enc.SetIndent("", "  ")
 	return enc.Encode(env)
 }
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/z1j1e/porthog/internal/adapters/output"
 	"github.com/z1j1e/porthog/internal/core/domain"
@@ -87,3 +88,273 @@ func TestPlainOutput(t *testing.T) {
 		t.Errorf("expected process name in output: %s", out)
 	}
 }
+
+func TestTableOutput_StateNames(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{
+			Protocol: domain.TCP, LocalIP: net.IPv4(10, 0, 0, 5), LocalPort: 443,
+			RemoteIP: net.IPv4(10, 0, 0, 9), RemotePort: 51000,
+			State: domain.StateFinWait2, PID: 7,
+		},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	r := output.NewRenderer(&buf, output.FormatTable)
+	if err := r.Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("FIN_WAIT2")) {
+		t.Errorf("expected FIN_WAIT2 in table output: %s", buf.String())
+	}
+}
+
+func TestTableOutput_ContainerColumn(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 3000, State: domain.StateListen, PID: 42,
+			Process: &domain.ProcessIdentity{PID: 42, Name: "node",
+				ContainerRuntime: "docker", ContainerID: "3f2a1b4c5d6e7f8091a2b3c4"}},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatTable).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("CONTAINER")) || !bytes.Contains(buf.Bytes(), []byte("docker:3f2a1b4c5d6e")) {
+		t.Errorf("expected container column in table output: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"id": "3f2a1b4c5d6e7f8091a2b3c4"`)) {
+		t.Errorf("expected full container ID in JSON: %s", buf.String())
+	}
+}
+
+func TestTableOutput_ProjectColumn(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 5173, State: domain.StateListen, PID: 42,
+			Process: &domain.ProcessIdentity{PID: 42, Name: "node",
+				Cwd: "/home/dev/src/shop/web", ProjectRoot: "/home/dev/src/shop/web"}},
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 22, State: domain.StateListen, PID: 7,
+			Process: &domain.ProcessIdentity{PID: 7, Name: "sshd", Cwd: "/"}},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatTable).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("PROJECT")) || !bytes.Contains(buf.Bytes(), []byte("web")) {
+		t.Errorf("expected project column in table output: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"project_root": "/home/dev/src/shop/web"`)) {
+		t.Errorf("expected project root in JSON: %s", buf.String())
+	}
+}
+
+func TestTableOutput_AppColumn(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 8000, State: domain.StateListen, PID: 42,
+			Process: &domain.ProcessIdentity{PID: 42, Name: "python3", AppLabel: "python:uvicorn app.main:app"}},
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 22, State: domain.StateListen, PID: 7,
+			Process: &domain.ProcessIdentity{PID: 7, Name: "sshd"}},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatTable).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("APP")) || !bytes.Contains(buf.Bytes(), []byte("python:uvicorn")) {
+		t.Errorf("expected app column in output: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"app": "python:uvicorn app.main:app"`)) {
+		t.Errorf("expected app label in JSON: %s", buf.String())
+	}
+}
+
+func TestTableOutput_UsageColumns(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 8080, State: domain.StateListen, PID: 42,
+			Process: &domain.ProcessIdentity{PID: 42, Name: "java",
+				CreateTimeMs: time.Now().Add(-(26*time.Hour + 5*time.Minute)).UnixMilli(),
+				Usage:        &domain.ProcessUsage{CPUPercent: 187.25, RSS: 512 << 20, FDs: 311}}},
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 9090, State: domain.StateListen, PID: 43,
+			Process: &domain.ProcessIdentity{PID: 43, Name: "node",
+				Usage: &domain.ProcessUsage{CPUPercent: -1, FDs: -1}}},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	r := output.NewRenderer(&buf, output.FormatPlain)
+	if err := r.SetColumns(append([]string{"pid"}, output.UsageColumns...)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	want := "42\t187.2\t512.0MiB\t311\t1d02h\n43\t-\t-\t-\t-\n"
+	if buf.String() != want {
+		t.Errorf("usage columns = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"cpu_percent": 187.3`, `"rss_bytes": 536870912`, `"fds": 311`, `"uptime_s": 93`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected %s in JSON: %s", want, buf.String())
+		}
+	}
+	if bytes.Count(buf.Bytes(), []byte(`"cpu_percent"`)) != 1 {
+		t.Errorf("expected no CPU figure for the unmeasured process: %s", buf.String())
+	}
+}
+
+func TestTableOutput_InterfaceColumn(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, Family: domain.FamilyIPv6, LocalIP: net.ParseIP("fe80::1"), LocalPort: 8080,
+			State: domain.StateListen, IfIndex: 2, Interface: "eth0"},
+		{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4zero, LocalPort: 22, State: domain.StateListen},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatTable).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"IFACE", "[fe80::1%eth0]:8080", "*"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected %q in table output: %s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"interface": "eth0"`)) {
+		t.Errorf("expected interface in JSON: %s", buf.String())
+	}
+}
+
+func TestOutput_UnownedSockets(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 2049, State: domain.StateListen,
+			OwnerKind: domain.OwnerKernel, UID: 0, HasUID: true, Username: "root"},
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 5432, State: domain.StateListen,
+			OwnerKind: domain.OwnerHidden, UID: 26, HasUID: true, Username: "postgres"},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings, Partial: true, DeniedCount: 1}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatTable).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[kernel]", "[hidden]", "postgres", "1 sockets are held by processes hidden"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected %q in table output: %s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"owner": "kernel"`, `"uid": 0`, `"owner": "hidden"`, `"user": "postgres"`, `"partial": true`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("expected %s in JSON output: %s", want, buf.String())
+		}
+	}
+}
+
+func TestJSONOutput_Metrics(t *testing.T) {
+	bindings := []domain.PortBinding{
+		{
+			Protocol: domain.TCP, LocalIP: net.IPv4(127, 0, 0, 1), LocalPort: 8080,
+			State: domain.StateEstablished, PID: 1,
+			Metrics: &domain.ConnMetrics{
+				RecvQueue: 3, RTT: 1500 * time.Microsecond, Cwnd: 10,
+				BytesSent: 4096, HasTCPInfo: true,
+			},
+		},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+
+	var envelope struct {
+		Data []struct {
+			Metrics map[string]float64 `json:"metrics"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &envelope); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	m := envelope.Data[0].Metrics
+	if m["recv_queue"] != 3 || m["rtt_ms"] != 1.5 || m["cwnd"] != 10 || m["bytes_sent"] != 4096 {
+		t.Errorf("unexpected metrics: %v", m)
+	}
+}
+
+func TestOutput_WideCommandLineAndAncestry(t *testing.T) {
+	cmdline := "python3 -m uvicorn app.main:app --port 8000 --workers 4 --log-level debug --root-path /api/v1/internal/services"
+	bindings := []domain.PortBinding{
+		{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 8000, State: domain.StateListen, PID: 42,
+			Process: &domain.ProcessIdentity{PID: 42, Name: "python3", Cmdline: cmdline,
+				Ancestors: []domain.ProcessRef{{PID: 41, Name: "sh"}, {PID: 40, Name: "make"}}}},
+	}
+	result := &domain.PartialResult[[]domain.PortBinding]{Data: bindings}
+
+	var buf bytes.Buffer
+	if err := output.NewRenderer(&buf, output.FormatTable).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("COMMAND")) {
+		t.Errorf("command line shown without wide mode: %s", buf.String())
+	}
+
+	for _, format := range []output.Format{output.FormatTable, output.FormatPlain} {
+		buf.Reset()
+		r := output.NewRenderer(&buf, format)
+		r.SetWide(true)
+		if err := r.Render(result, "list"); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte(cmdline)) {
+			t.Errorf("format %d: expected the untruncated command line: %s", format, buf.String())
+		}
+		if !bytes.Contains(buf.Bytes(), []byte("python3 ← sh")) {
+			t.Errorf("format %d: expected the ancestry: %s", format, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := output.NewRenderer(&buf, output.FormatJSON).Render(result, "list"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"cmdline": "`+cmdline+`"`)) {
+		t.Errorf("expected cmdline in JSON: %s", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"name": "make"`)) {
+		t.Errorf("expected ancestors in JSON: %s", buf.String())
+	}
+}
@@ -2,18 +2,31 @@ package output
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 )
 
 func (r *Renderer) renderPlain(bindings []domain.PortBinding) error {
-	for _, b := range bindings {
-		name := "-"
-		if b.Process != nil && b.Process.Name != "" {
-			name = b.Process.Name
+	if r.columns != nil || r.wide {
+		return r.renderPlainColumns(bindings)
+	}
+	for i := range bindings {
+		b := &bindings[i]
+		fmt.Fprintf(r.w, "%s\t%s\t%d\t%s\t%s\n",
+			b.Protocol, b.LocalAddr(), b.PID, b.OwnerName(), b.State)
+	}
+	return nil
+}
+
+func (r *Renderer) renderPlainColumns(bindings []domain.PortBinding) error {
+	keys := r.columnKeys(bindings)
+	for i := range bindings {
+		cells := make([]string, len(keys))
+		for j, k := range keys {
+			cells[j] = columns[k].value(&bindings[i])
 		}
-		fmt.Fprintf(r.w, "%s\t%s:%d\t%d\t%s\t%s\n",
-			b.Protocol, b.LocalIP, b.LocalPort, b.PID, name, b.State)
+		fmt.Fprintln(r.w, strings.Join(cells, "\t"))
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"fmt"
 	"io"
 	"os"
 
@@ -22,14 +23,34 @@ const (
 
 // Renderer writes port bindings to an output stream.
 type Renderer struct {
-	w      io.Writer
-	format Format
+	w       io.Writer
+	format  Format
+	columns []string
+	wide    bool
 }
 
 func NewRenderer(w io.Writer, format Format) *Renderer {
 	return &Renderer{w: w, format: format}
 }
 
+// SetColumns selects the table columns by key (see DefaultColumns). Plain
+// output uses the same columns, tab-separated, once they have been set.
+func (r *Renderer) SetColumns(keys []string) error {
+	for _, k := range keys {
+		if _, ok := columns[k]; !ok {
+			return fmt.Errorf("unknown column %q", k)
+		}
+	}
+	r.columns = keys
+	return nil
+}
+
+// SetWide adds each process's command line as the last column, in full, to
+// table and plain output, and shows its ancestry, e.g. "node ← npm ← zsh".
+func (r *Renderer) SetWide(wide bool) {
+	r.wide = wide
+}
+
 // Render outputs port bindings in the configured format.
 func (r *Renderer) Render(result *domain.PartialResult[[]domain.PortBinding], cmd string) error {
 	f := r.resolveFormat()
@@ -39,7 +60,14 @@ func (r *Renderer) Render(result *domain.PartialResult[[]domain.PortBinding], cm
 	case FormatPlain:
 		return r.renderPlain(result.Data)
 	default:
-		return r.renderTable(result.Data)
+		if err := r.renderTable(result.Data); err != nil {
+			return err
+		}
+		if result.DeniedCount > 0 {
+			fmt.Fprintln(r.w, noteStyle.Render(fmt.Sprintf(
+				"\n%d sockets are held by processes hidden from this user; run as root to see their owners.", result.DeniedCount)))
+		}
+		return nil
 	}
 }
 
@@ -2,7 +2,9 @@ package output
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 
@@ -13,26 +15,178 @@ var (
 	headerStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
 	cellStyle   = lipgloss.NewStyle()
 	pidStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-	protoTCP    = lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("TCP")
-	protoUDP    = lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Render("UDP")
+	noteStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	protoStyles = map[domain.Protocol]lipgloss.Style{
+		domain.TCP:  lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+		domain.UDP:  lipgloss.NewStyle().Foreground(lipgloss.Color("13")),
+		domain.Unix: lipgloss.NewStyle().Foreground(lipgloss.Color("14")),
+		domain.SCTP: lipgloss.NewStyle().Foreground(lipgloss.Color("208")),
+		domain.Raw:  lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		domain.ICMP: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	}
 )
 
+// DefaultColumns is the standard table layout.
+var DefaultColumns = []string{"proto", "local_addr", "pid", "process", "user", "state"}
+
+// MetricsColumns is the table layout used by `list --metrics`.
+var MetricsColumns = []string{
+	"proto", "local_addr", "remote_addr", "state", "recv_q", "send_q",
+	"rtt", "cwnd", "retrans", "sent", "recv", "process",
+}
+
+// UsageColumns are added to the table by `list --resources`.
+var UsageColumns = []string{"cpu", "rss", "fds", "uptime"}
+
+// column describes a table column: its header, sizing hints and how to
+// render a binding's cell. Columns are looked up by config key.
+type column struct {
+	header string
+	min    int
+	weight int  // share of spare terminal width
+	narrow bool // dropped when the terminal is narrower than 80 columns
+	full   bool // never truncated when it is the last column
+	value  func(b *domain.PortBinding) string
+	style  func(b *domain.PortBinding) lipgloss.Style
+}
+
+var columns = map[string]column{
+	"proto": {header: "PROTO", min: 5,
+		value: func(b *domain.PortBinding) string { return strings.ToUpper(b.Protocol.String()) },
+		style: func(b *domain.PortBinding) lipgloss.Style { return protoStyles[b.Protocol] }},
+	"local_addr": {header: "LOCAL ADDRESS", min: 15, weight: 2,
+		value: func(b *domain.PortBinding) string { return b.LocalAddr() }},
+	"remote_addr": {header: "REMOTE ADDRESS", min: 15, weight: 2,
+		value: func(b *domain.PortBinding) string { return orDash(b.RemoteAddr()) }},
+	"pid": {header: "PID", min: 9,
+		value: pidValue,
+		style: func(*domain.PortBinding) lipgloss.Style { return pidStyle }},
+	"process": {header: "PROCESS", min: 8, weight: 3,
+		value: func(b *domain.PortBinding) string { return b.OwnerName() }},
+	"lineage": {header: "PROCESS", min: 8, weight: 4,
+		value: func(b *domain.PortBinding) string {
+			if b.Process != nil && len(b.Owners) <= 1 {
+				return b.Process.Lineage()
+			}
+			return b.OwnerName()
+		}},
+	"app": {header: "APP", min: 8, weight: 3,
+		value: func(b *domain.PortBinding) string {
+			if b.Process != nil {
+				return orDash(b.Process.AppLabel)
+			}
+			return "-"
+		}},
+	"project": {header: "PROJECT", min: 8, weight: 2,
+		value: func(b *domain.PortBinding) string {
+			if b.Process != nil {
+				return orDash(b.Process.ProjectName())
+			}
+			return "-"
+		}},
+	"container": {header: "CONTAINER", min: 12, weight: 2,
+		value: func(b *domain.PortBinding) string {
+			if b.Process != nil && b.Process.InContainer() {
+				return b.Process.ContainerRuntime + ":" + b.Process.ShortContainerID()
+			}
+			return "-"
+		}},
+	"unit": {header: "UNIT", min: 10, weight: 2, narrow: true,
+		value: func(b *domain.PortBinding) string {
+			if b.SocketUnit != "" {
+				return b.SocketUnit
+			}
+			if b.Process != nil {
+				return orDash(b.Process.SystemdUnit)
+			}
+			return "-"
+		}},
+	"user": {header: "USER", min: 8, weight: 2, narrow: true,
+		value: func(b *domain.PortBinding) string { return orDash(b.OwnerUser()) }},
+	"state": {header: "STATE", min: 11, weight: 1,
+		value: func(b *domain.PortBinding) string { return b.State.String() }},
+	"netns": {header: "NETNS", min: 10, weight: 2,
+		value: func(b *domain.PortBinding) string {
+			if b.Netns != nil {
+				return b.Netns.String()
+			}
+			return "-"
+		}},
+	"iface": {header: "IFACE", min: 5, weight: 1,
+		value: func(b *domain.PortBinding) string {
+			switch {
+			case b.Interface != "":
+				return b.Interface
+			case b.IsWildcard():
+				return "*"
+			}
+			return "-"
+		}},
+	"cmdline": {header: "COMMAND", min: 20, weight: 4, full: true,
+		value: func(b *domain.PortBinding) string {
+			if b.Process != nil {
+				return orDash(b.Process.Cmdline)
+			}
+			return "-"
+		}},
+	"cpu": {header: "CPU%", min: 5,
+		value: usageValue(func(u *domain.ProcessUsage) string {
+			if !u.HasCPU() {
+				return "-"
+			}
+			return fmt.Sprintf("%.1f", u.CPUPercent)
+		})},
+	"rss": {header: "RSS", min: 8,
+		value: usageValue(func(u *domain.ProcessUsage) string {
+			if u.RSS == 0 {
+				return "-"
+			}
+			return formatBytes(u.RSS)
+		})},
+	"fds": {header: "FDS", min: 5,
+		value: usageValue(func(u *domain.ProcessUsage) string {
+			if u.FDs < 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%d", u.FDs)
+		})},
+	"uptime": {header: "UPTIME", min: 7,
+		value: func(b *domain.PortBinding) string {
+			if b.Process == nil || b.Process.CreateTimeMs == 0 {
+				return "-"
+			}
+			return formatUptime(b.Process.Uptime(time.Now()))
+		}},
+	"recv_q": {header: "RECV-Q", min: 6,
+		value: metricValue(func(m *domain.ConnMetrics) string { return fmt.Sprintf("%d", m.RecvQueue) })},
+	"send_q": {header: "SEND-Q", min: 6,
+		value: metricValue(func(m *domain.ConnMetrics) string { return fmt.Sprintf("%d", m.SendQueue) })},
+	"rtt": {header: "RTT/VAR", min: 13,
+		value: tcpMetricValue(func(m *domain.ConnMetrics) string {
+			return fmt.Sprintf("%s/%s", formatRTT(m.RTT), formatRTT(m.RTTVar))
+		})},
+	"cwnd": {header: "CWND", min: 5,
+		value: tcpMetricValue(func(m *domain.ConnMetrics) string { return fmt.Sprintf("%d", m.Cwnd) })},
+	"retrans": {header: "RETRANS", min: 7,
+		value: tcpMetricValue(func(m *domain.ConnMetrics) string { return fmt.Sprintf("%d", m.Retransmits) })},
+	"sent": {header: "SENT", min: 7,
+		value: tcpMetricValue(func(m *domain.ConnMetrics) string { return formatBytes(m.BytesSent) })},
+	"recv": {header: "RECV", min: 7,
+		value: tcpMetricValue(func(m *domain.ConnMetrics) string { return formatBytes(m.BytesReceived) })},
+}
+
 func (r *Renderer) renderTable(bindings []domain.PortBinding) error {
 	tw := r.termWidth()
 
-	// Column definitions: name, minWidth, weight (for distributing extra space)
-	type col struct {
-		header string
-		min    int
-		weight int
-	}
-	cols := []col{
-		{"PROTO", 5, 0},
-		{"LOCAL ADDRESS", 15, 2},
-		{"PID", 7, 0},
-		{"PROCESS", 8, 3},
-		{"USER", 8, 2},
-		{"STATE", 6, 1},
+	keys := r.columnKeys(bindings)
+	// Narrow mode: hide low-priority columns (USER) if terminal < 80
+	var cols []column
+	for _, k := range keys {
+		c := columns[k]
+		if c.narrow && tw < 80 {
+			continue
+		}
+		cols = append(cols, c)
 	}
 
 	// Calculate adaptive widths
@@ -56,15 +210,9 @@ func (r *Renderer) renderTable(bindings []domain.PortBinding) error {
 		}
 	}
 
-	// Narrow mode: hide USER column if terminal < 80
-	showUser := tw >= 80
-
 	// Header
 	var hdr strings.Builder
 	for i, c := range cols {
-		if i == 4 && !showUser {
-			continue
-		}
 		hdr.WriteString(headerStyle.Width(widths[i]).Render(c.header))
 		if i < len(cols)-1 {
 			hdr.WriteString("  ")
@@ -79,46 +227,191 @@ func (r *Renderer) renderTable(bindings []domain.PortBinding) error {
 	fmt.Fprintln(r.w, strings.Repeat("─", sepWidth))
 
 	// Rows
-	for _, b := range bindings {
-		proto := protoTCP
-		if b.Protocol == domain.UDP {
-			proto = protoUDP
-		}
-		addr := fmt.Sprintf("%s:%d", b.LocalIP, b.LocalPort)
-		pid := pidStyle.Render(fmt.Sprintf("%d", b.PID))
-		name, user := "-", "-"
-		if b.Process != nil {
-			if b.Process.Name != "" {
-				name = b.Process.Name
+	for i := range bindings {
+		b := &bindings[i]
+		var row strings.Builder
+		for j, c := range cols {
+			cell := c.value(b)
+			if !c.full || j < len(cols)-1 {
+				cell = truncate(cell, widths[j])
+			}
+			if j < len(cols)-1 {
+				cell = fmt.Sprintf("%-*s", widths[j], cell)
 			}
-			if b.Process.Username != "" {
-				user = b.Process.Username
+			style := cellStyle
+			if c.style != nil {
+				style = c.style(b)
+			}
+			row.WriteString(style.Render(cell))
+			if j < len(cols)-1 {
+				row.WriteString("  ")
 			}
 		}
+		fmt.Fprintln(r.w, row.String())
+	}
+	return nil
+}
 
-		if showUser {
-			fmt.Fprintf(r.w, "%-*s  %-*s  %-*s  %-*s  %-*s  %s\n",
-				widths[0], proto,
-				widths[1], truncate(addr, widths[1]),
-				widths[2], pid,
-				widths[3], cellStyle.Render(truncate(name, widths[3])),
-				widths[4], cellStyle.Render(truncate(user, widths[4])),
-				truncate(string(b.State), widths[5]))
-		} else {
-			fmt.Fprintf(r.w, "%-*s  %-*s  %-*s  %-*s  %s\n",
-				widths[0], proto,
-				widths[1], truncate(addr, widths[1]),
-				widths[2], pid,
-				widths[3], cellStyle.Render(truncate(name, widths[3])),
-				truncate(string(b.State), widths[5]))
+// columnKeys returns the columns to show: those set with SetColumns or the
+// defaults for bindings. Wide mode shows each process's ancestry in the
+// process column and adds the command line.
+func (r *Renderer) columnKeys(bindings []domain.PortBinding) []string {
+	keys := r.columns
+	if keys == nil {
+		keys = defaultColumns(bindings)
+	}
+	if !r.wide {
+		return keys
+	}
+	keys = slices.Clone(keys)
+	if i := slices.Index(keys, "process"); i >= 0 {
+		keys[i] = "lineage"
+	}
+	if !slices.Contains(keys, "cmdline") {
+		keys = append(keys, "cmdline")
+	}
+	return keys
+}
+
+// optionalColumns are added to DefaultColumns, after the column named by
+// after, when at least one binding has something to show in them.
+var optionalColumns = []struct {
+	key, after string
+	present    func(b *domain.PortBinding) bool
+}{
+	{"iface", "local_addr", func(b *domain.PortBinding) bool {
+		return b.IfIndex != 0
+	}},
+	{"app", "process", func(b *domain.PortBinding) bool {
+		return b.Process != nil && b.Process.AppLabel != ""
+	}},
+	{"project", "app", func(b *domain.PortBinding) bool {
+		return b.Process != nil && b.Process.ProjectRoot != ""
+	}},
+	{"container", "project", func(b *domain.PortBinding) bool {
+		return b.Process != nil && b.Process.InContainer()
+	}},
+	{"unit", "container", func(b *domain.PortBinding) bool {
+		return b.SocketUnit != "" || (b.Process != nil && b.Process.SystemdUnit != "")
+	}},
+}
+
+// defaultColumns is DefaultColumns plus whichever optional columns apply to
+// bindings.
+func defaultColumns(bindings []domain.PortBinding) []string {
+	keys := DefaultColumns
+	for _, opt := range optionalColumns {
+		for i := range bindings {
+			if opt.present(&bindings[i]) {
+				keys = insertColumn(keys, opt.key, opt.after)
+				break
+			}
 		}
 	}
-	return nil
+	return keys
+}
+
+// insertColumn returns a copy of keys with key placed after the column
+// after, or after "process" when after is not shown.
+func insertColumn(keys []string, key, after string) []string {
+	pos := -1
+	for i, k := range keys {
+		if k == after {
+			pos = i
+		}
+		if pos < 0 && k == "process" {
+			pos = i
+		}
+	}
+	out := make([]string, 0, len(keys)+1)
+	out = append(out, keys[:pos+1]...)
+	out = append(out, key)
+	return append(out, keys[pos+1:]...)
+}
+
+// pidValue collapses shared sockets to the primary PID plus a count of the
+// other holders, e.g. "1234+3" for an nginx master and three workers.
+// Sockets known to have no owning process show "-".
+func pidValue(b *domain.PortBinding) string {
+	if b.PID == 0 && b.OwnerKind > domain.OwnerProcess {
+		return "-"
+	}
+	if n := len(b.Owners); n > 1 {
+		return fmt.Sprintf("%d+%d", b.PID, n-1)
+	}
+	return fmt.Sprintf("%d", b.PID)
+}
+
+func metricValue(f func(m *domain.ConnMetrics) string) func(b *domain.PortBinding) string {
+	return func(b *domain.PortBinding) string {
+		if b.Metrics == nil {
+			return "-"
+		}
+		return f(b.Metrics)
+	}
+}
+
+func tcpMetricValue(f func(m *domain.ConnMetrics) string) func(b *domain.PortBinding) string {
+	return func(b *domain.PortBinding) string {
+		if b.Metrics == nil || !b.Metrics.HasTCPInfo {
+			return "-"
+		}
+		return f(b.Metrics)
+	}
+}
+
+func usageValue(f func(u *domain.ProcessUsage) string) func(b *domain.PortBinding) string {
+	return func(b *domain.PortBinding) string {
+		if b.Process == nil || b.Process.Usage == nil {
+			return "-"
+		}
+		return f(b.Process.Usage)
+	}
+}
+
+// formatUptime renders d in its two largest units, e.g. "3d04h", "2h05m",
+// "12m30s".
+func formatUptime(d time.Duration) string {
+	s := int64(d / time.Second)
+	switch {
+	case s < 60:
+		return fmt.Sprintf("%ds", max(s, 0))
+	case s < 3600:
+		return fmt.Sprintf("%dm%02ds", s/60, s%60)
+	case s < 86400:
+		return fmt.Sprintf("%dh%02dm", s/3600, s%3600/60)
+	}
+	return fmt.Sprintf("%dd%02dh", s/86400, s%86400/3600)
+}
+
+func formatRTT(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
 
 func truncate(s string, max int) string {
-	if len(s) <= max {
+	r := []rune(s)
+	if len(r) <= max {
 		return s
 	}
-	return s[:max-1] + "…"
+	return string(r[:max-1]) + "…"
 }
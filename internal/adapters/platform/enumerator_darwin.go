@@ -10,3 +10,9 @@ import (
 func NewEnumerator() ports.Enumerator {
 	return darwinEnum.NewEnumerator()
 }
+
+// NewEnumeratorWithOptions returns the platform-specific port enumerator.
+// None of the optional data is available here, so opts is ignored.
+func NewEnumeratorWithOptions(_ ports.EnumeratorOptions) ports.Enumerator {
+	return darwinEnum.NewEnumerator()
+}
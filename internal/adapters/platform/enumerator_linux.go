@@ -10,3 +10,21 @@ import (
 func NewEnumerator() ports.Enumerator {
 	return linuxEnum.NewEnumerator()
 }
+
+// NewEnumeratorWithOptions returns the platform-specific port enumerator
+// configured with opts.
+func NewEnumeratorWithOptions(opts ports.EnumeratorOptions) ports.Enumerator {
+	return linuxEnum.NewEnumeratorWithOptions(opts)
+}
+
+// NewWatcher returns a watcher that reports socket changes as they happen
+// where sock_diag notifications are available.
+func NewWatcher(opts ports.EnumeratorOptions) ports.Watcher {
+	return linuxEnum.NewWatcher(linuxEnum.NewEnumeratorWithOptions(opts))
+}
+
+// NewProcessMonitor returns a monitor reporting process exec and exit events
+// from the kernel's process events connector.
+func NewProcessMonitor() ports.ProcessMonitor {
+	return linuxEnum.NewProcMonitor()
+}
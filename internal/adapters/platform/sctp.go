@@ -0,0 +1,12 @@
+package platform
+
+import (
+	linuxEnum "github.com/z1j1e/porthog/internal/adapters/os/linux"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+// NewSCTPProber returns the SCTP bind checker, which reports SCTP as
+// unsupported outside Linux.
+func NewSCTPProber() ports.SCTPProber {
+	return linuxEnum.NewSCTPProber()
+}
@@ -4,13 +4,20 @@ package platform
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"syscall"
 	"time"
 
+	"github.com/z1j1e/porthog/internal/core/domain"
 	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
+// gracePeriod is how long a process has to exit after SIGTERM before it is
+// sent SIGKILL.
+const gracePeriod = 2 * time.Second
+
 type unixTerminator struct{}
 
 func NewTerminator() ports.Terminator { return &unixTerminator{} }
@@ -22,21 +29,40 @@ func (t *unixTerminator) Terminate(ctx context.Context, pid int32, policy ports.
 	}
 
 	if policy.Force {
-		return p.Signal(syscall.SIGKILL)
+		return exitedErr(p.Signal(syscall.SIGKILL))
 	}
 
-	// Graceful: SIGTERM, wait 2s, then SIGKILL
+	// Graceful: SIGTERM, wait up to 2s, then SIGKILL
 	if err := p.Signal(syscall.SIGTERM); err != nil {
-		return err
+		return exitedErr(err)
 	}
 
-	timer := time.NewTimer(2 * time.Second)
-	defer timer.Stop()
-	select {
-	case <-timer.C:
-	case <-ctx.Done():
-		return ctx.Err()
+	deadline := time.NewTimer(gracePeriod)
+	defer deadline.Stop()
+	poll := time.NewTicker(50 * time.Millisecond)
+	defer poll.Stop()
+	for {
+		select {
+		case <-deadline.C:
+			if err := p.Signal(syscall.SIGKILL); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return err
+			}
+			return nil
+		case <-poll.C:
+			if errors.Is(p.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
+}
 
-	return p.Signal(syscall.SIGKILL)
+// exitedErr reports a process that was gone before it could be signalled as
+// domain.ErrProcessExited.
+func exitedErr(err error) error {
+	if errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("%w: %v", domain.ErrProcessExited, err)
+	}
+	return err
 }
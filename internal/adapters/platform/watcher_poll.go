@@ -0,0 +1,45 @@
+//go:build !linux
+
+package platform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+)
+
+// pollWatcher takes snapshots with an enumerator. The platform has no socket
+// change notifications, so watch mode polls.
+type pollWatcher struct {
+	enum ports.Enumerator
+}
+
+// NewWatcher returns a watcher that can only be polled on this platform.
+func NewWatcher(opts ports.EnumeratorOptions) ports.Watcher {
+	return &pollWatcher{enum: NewEnumeratorWithOptions(opts)}
+}
+
+func (w *pollWatcher) Snapshot(ctx context.Context, filter *domain.Filter) (*ports.Snapshot, error) {
+	result, err := w.enum.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &ports.Snapshot{Bindings: result.Data, Partial: result.Partial}, nil
+}
+
+func (w *pollWatcher) Events(context.Context, *domain.Filter) (<-chan ports.WatchEvent, error) {
+	return nil, fmt.Errorf("%w: socket change notifications", domain.ErrUnsupported)
+}
+
+// noProcessMonitor reports no process events, leaving cached process
+// metadata to expire by TTL.
+type noProcessMonitor struct{}
+
+// NewProcessMonitor returns a monitor that is unsupported on this platform.
+func NewProcessMonitor() ports.ProcessMonitor { return noProcessMonitor{} }
+
+func (noProcessMonitor) Events(context.Context) (<-chan ports.ProcessEvent, error) {
+	return nil, fmt.Errorf("%w: process events", domain.ErrUnsupported)
+}
@@ -0,0 +1,257 @@
+package process
+
+import (
+	"debug/buildinfo"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AppProcess is what an AppDetector sees of a process.
+type AppProcess struct {
+	Argv []string
+	Exe  string // executable path as the process sees it
+	// ExeFile is where the executable can be opened from, which differs
+	// from Exe inside containers or when it was deleted; empty if unknown.
+	ExeFile string
+}
+
+// AppDetector derives an application label, such as
+// "java:com.acme.ApiServer", for processes of one runtime. It returns ""
+// for processes it does not recognise.
+type AppDetector interface {
+	DetectApp(p *AppProcess) string
+}
+
+// AppDetectorFunc adapts a function to AppDetector.
+type AppDetectorFunc func(p *AppProcess) string
+
+func (f AppDetectorFunc) DetectApp(p *AppProcess) string { return f(p) }
+
+// DefaultAppDetectors recognise JVM, Node, Python, Ruby and .NET processes
+// by their launcher and Go programs by their embedded build information.
+var DefaultAppDetectors = []AppDetector{
+	AppDetectorFunc(detectJava),
+	AppDetectorFunc(detectNode),
+	AppDetectorFunc(detectPython),
+	AppDetectorFunc(detectRuby),
+	AppDetectorFunc(detectDotnet),
+	AppDetectorFunc(detectGo),
+}
+
+// detectApp returns the label of the first detector that recognises p.
+func detectApp(detectors []AppDetector, p *AppProcess) string {
+	if len(p.Argv) == 0 {
+		return ""
+	}
+	for _, d := range detectors {
+		if label := d.DetectApp(p); label != "" {
+			return label
+		}
+	}
+	return ""
+}
+
+// launcher returns the base name of the program argv[0] ran, without a
+// Windows .exe suffix.
+func launcher(p *AppProcess) string {
+	return strings.TrimSuffix(strings.ToLower(baseName(p.Argv[0])), ".exe")
+}
+
+// baseName is filepath.Base for both slash and backslash separated paths,
+// whichever platform the process runs on.
+func baseName(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// firstOperand returns the index of the first argument after argv[0] that
+// is not an option, skipping the values of the options in withValue.
+func firstOperand(argv []string, withValue map[string]bool) int {
+	for i := 1; i < len(argv); i++ {
+		a := argv[i]
+		switch {
+		case a == "--":
+			if i+1 < len(argv) {
+				return i + 1
+			}
+			return -1
+		case withValue[a]:
+			i++
+		case !strings.HasPrefix(a, "-") || a == "-":
+			return i
+		}
+	}
+	return -1
+}
+
+// scriptName shortens a script path the way it is usually talked about:
+// package entry points (under node_modules, or installed without an
+// extension) by their name, anything else as it was given.
+func scriptName(path string) string {
+	base := baseName(path)
+	if strings.Contains(filepath.ToSlash(path), "node_modules/") {
+		return strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if filepath.Ext(base) == "" {
+		return base
+	}
+	return path
+}
+
+var javaOptionsWithValue = map[string]bool{
+	"-cp": true, "-classpath": true, "--class-path": true, "-p": true, "--module-path": true,
+	"--add-modules": true, "--add-opens": true, "--add-exports": true, "--add-reads": true,
+	"--upgrade-module-path": true, "--limit-modules": true, "--patch-module": true,
+}
+
+func detectJava(p *AppProcess) string {
+	if launcher(p) != "java" && launcher(p) != "javaw" {
+		return ""
+	}
+	for i := 1; i < len(p.Argv); i++ {
+		switch a := p.Argv[i]; {
+		case a == "-jar" && i+1 < len(p.Argv):
+			return "java:" + baseName(p.Argv[i+1])
+		case (a == "-m" || a == "--module") && i+1 < len(p.Argv):
+			return "java:" + p.Argv[i+1]
+		case strings.HasPrefix(a, "--module="):
+			return "java:" + strings.TrimPrefix(a, "--module=")
+		case javaOptionsWithValue[a]:
+			i++
+		case !strings.HasPrefix(a, "-"):
+			return "java:" + a
+		}
+	}
+	return ""
+}
+
+var nodeOptionsWithValue = map[string]bool{
+	"-r": true, "--require": true, "--import": true, "--loader": true, "--experimental-loader": true,
+	"--env-file": true, "--title": true, "--conditions": true, "-C": true,
+}
+
+func detectNode(p *AppProcess) string {
+	switch launcher(p) {
+	case "node", "nodejs":
+	default:
+		return ""
+	}
+	for _, a := range p.Argv[1:] {
+		if a == "-e" || a == "--eval" || a == "-p" || a == "--print" {
+			return "node:" + a
+		}
+	}
+	if i := firstOperand(p.Argv, nodeOptionsWithValue); i > 0 {
+		return "node:" + scriptName(p.Argv[i])
+	}
+	return ""
+}
+
+var (
+	pythonLauncher = regexp.MustCompile(`^(?:python|pypy)[0-9.]*w?$`)
+	// pythonAppSpec matches the application argument of ASGI/WSGI servers
+	// and task runners, e.g. "app.main:app" or "wsgi:create_app()".
+	pythonAppSpec = regexp.MustCompile(`^[A-Za-z_][\w.]*:[A-Za-z_][\w.]*(?:\(\))?$`)
+
+	pythonOptionsWithValue = map[string]bool{"-W": true, "-X": true, "--check-hash-based-pycs": true}
+)
+
+func detectPython(p *AppProcess) string {
+	if !pythonLauncher.MatchString(launcher(p)) {
+		return ""
+	}
+	i := firstOperand(p.Argv, pythonOptionsWithValue)
+	for j := 1; j < len(p.Argv) && (i < 0 || j < i); j++ {
+		switch a := p.Argv[j]; {
+		case a == "-c":
+			return "python:-c"
+		case a == "-m" && j+1 < len(p.Argv):
+			return "python:" + withAppSpec(p.Argv[j+1], p.Argv[j+2:])
+		case strings.HasPrefix(a, "-m") && len(a) > 2:
+			return "python:" + withAppSpec(a[2:], p.Argv[j+1:])
+		}
+	}
+	if i < 0 {
+		return ""
+	}
+	return "python:" + withAppSpec(scriptName(p.Argv[i]), p.Argv[i+1:])
+}
+
+// withAppSpec appends the first argument in args that names a Python
+// application object to what runs it, e.g. "uvicorn app.main:app".
+func withAppSpec(runner string, args []string) string {
+	for _, a := range args {
+		if pythonAppSpec.MatchString(a) {
+			return runner + " " + a
+		}
+	}
+	return runner
+}
+
+var rubyOptionsWithValue = map[string]bool{"-I": true, "-r": true, "-C": true, "-E": true}
+
+func detectRuby(p *AppProcess) string {
+	if !strings.HasPrefix(launcher(p), "ruby") {
+		return ""
+	}
+	for _, a := range p.Argv[1:] {
+		if a == "-e" {
+			return "ruby:-e"
+		}
+	}
+	i := firstOperand(p.Argv, rubyOptionsWithValue)
+	if i < 0 {
+		return ""
+	}
+	// bin/rails server, bundle exec puma: the subcommand says more
+	name := scriptName(p.Argv[i])
+	if (name == "rails" || name == "bundle") && i+1 < len(p.Argv) {
+		name += " " + strings.Join(p.Argv[i+1:min(i+3, len(p.Argv))], " ")
+	}
+	return "ruby:" + name
+}
+
+var dotnetOptionsWithValue = map[string]bool{"--depsfile": true, "--runtimeconfig": true, "--additionalprobingpath": true}
+
+func detectDotnet(p *AppProcess) string {
+	if launcher(p) != "dotnet" {
+		return ""
+	}
+	args := p.Argv
+	i := firstOperand(args, dotnetOptionsWithValue)
+	if i > 0 && args[i] == "exec" {
+		args = args[i:] // options follow exec as they follow dotnet
+		i = firstOperand(args, dotnetOptionsWithValue)
+	}
+	if i < 0 {
+		return ""
+	}
+	if strings.HasSuffix(strings.ToLower(args[i]), ".dll") {
+		return "dotnet:" + baseName(args[i])
+	}
+	return "dotnet:" + args[i] // an SDK command: dotnet run, dotnet watch
+}
+
+// detectGo labels programs carrying Go build information with the command
+// that started them, e.g. "go:./bin/api". Binaries built by `go run` live
+// in a temporary directory and are labelled by name.
+func detectGo(p *AppProcess) string {
+	file := p.ExeFile
+	if file == "" {
+		file = p.Exe
+	}
+	if file == "" {
+		return ""
+	}
+	if _, err := buildinfo.ReadFile(file); err != nil {
+		return ""
+	}
+	cmd := p.Argv[0]
+	if strings.Contains(filepath.ToSlash(cmd), "/go-build") {
+		cmd = baseName(cmd)
+	}
+	return "go:" + cmd
+}
@@ -0,0 +1,83 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectApp(t *testing.T) {
+	tests := []struct {
+		name, cmdline, want string
+	}{
+		{"java main class", "java -Xmx2g -Dspring.profiles.active=prod -cp app.jar:lib/* com.acme.ApiServer --port 8080", "java:com.acme.ApiServer"},
+		{"java jar", "/usr/lib/jvm/java-21/bin/java -jar /srv/billing/billing-1.4.jar", "java:billing-1.4.jar"},
+		{"java module", "java --module-path mods -m com.acme.api/com.acme.Main", "java:com.acme.api/com.acme.Main"},
+		{"node script", "node --enable-source-maps server.js", "node:server.js"},
+		{"node require", "node -r dotenv/config dist/index.js", "node:dist/index.js"},
+		{"node package bin", "node /app/node_modules/.bin/vite --port 5173", "node:vite"},
+		{"node package entry", "/usr/bin/node /app/node_modules/next/dist/bin/next dev", "node:next"},
+		{"node eval", "node -e require('http').createServer().listen(3000)", "node:-e"},
+		{"python uvicorn entry point", "/venv/bin/python3 /venv/bin/uvicorn --host 0.0.0.0 app.main:app", "python:uvicorn app.main:app"},
+		{"python module", "python3 -m uvicorn app.main:app --reload", "python:uvicorn app.main:app"},
+		{"python joined module", "python3 -u -mhttp.server 8000", "python:http.server"},
+		{"python gunicorn", "python /srv/venv/bin/gunicorn -w 4 -b 0.0.0.0:8000 wsgi:create_app()", "python:gunicorn wsgi:create_app()"},
+		{"python script", "python3.12 -X dev manage.py runserver", "python:manage.py"},
+		{"python command", "python3 -c import http.server", "python:-c"},
+		{"ruby rails", "ruby bin/rails server -p 3000", "ruby:rails server -p"},
+		{"ruby script", "ruby -Ilib app.rb", "ruby:app.rb"},
+		{"dotnet dll", "dotnet Acme.Api.dll --urls http://+:5000", "dotnet:Acme.Api.dll"},
+		{"dotnet exec", "dotnet exec --runtimeconfig x.json bin/Acme.Worker.dll", "dotnet:Acme.Worker.dll"},
+		{"dotnet sdk", "dotnet watch run", "dotnet:watch"},
+		{"windows java", `C:\Program Files\Java\bin\java.exe -jar C:\apps\api.jar`, `java:api.jar`},
+		{"unrecognised", "nginx: master process /usr/sbin/nginx", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argv := strings.Fields(tt.cmdline)
+			if strings.HasPrefix(tt.cmdline, `C:\Program Files`) {
+				argv = append([]string{`C:\Program Files\Java\bin\java.exe`}, argv[2:]...)
+			}
+			p := &AppProcess{Argv: argv, ExeFile: filepath.Join(t.TempDir(), "missing")}
+			if got := detectApp(DefaultAppDetectors, p); got != tt.want {
+				t.Errorf("detectApp(%q) = %q, want %q", tt.cmdline, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectGo(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	if got := detectGo(&AppProcess{Argv: []string{"./bin/api", "-addr", ":8080"}, ExeFile: self}); got != "go:./bin/api" {
+		t.Errorf("Go binary labelled %q, want go:./bin/api", got)
+	}
+	if got := detectGo(&AppProcess{Argv: []string{"/tmp/go-build1234/b001/exe/api"}, ExeFile: self}); got != "go:api" {
+		t.Errorf("go run binary labelled %q, want go:api", got)
+	}
+
+	other := filepath.Join(t.TempDir(), "script")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectGo(&AppProcess{Argv: []string{other}, ExeFile: other}); got != "" {
+		t.Errorf("non-Go executable labelled %q", got)
+	}
+}
+
+func TestDetectApp_CustomDetectorsComeFirst(t *testing.T) {
+	beam := AppDetectorFunc(func(p *AppProcess) string {
+		if launcher(p) == "beam.smp" {
+			return "erlang:" + p.Argv[len(p.Argv)-1]
+		}
+		return ""
+	})
+	detectors := append([]AppDetector{beam}, DefaultAppDetectors...)
+	p := &AppProcess{Argv: []string{"/usr/lib/erlang/erts/bin/beam.smp", "--", "-boot", "rabbit"}}
+	if got := detectApp(detectors, p); got != "erlang:rabbit" {
+		t.Errorf("detectApp() = %q, want erlang:rabbit", got)
+	}
+}
@@ -0,0 +1,122 @@
+package process
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// cacheKey identifies a process across PID reuse: a PID recycled for a new
+// process gets a new key, since the create time differs.
+type cacheKey struct {
+	pid      int32
+	createMs int64
+}
+
+type cacheEntry struct {
+	key       cacheKey
+	identity  domain.ProcessIdentity
+	expiresAt time.Time
+}
+
+// CacheStats counts identity cache lookups since the resolver was created.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64 // lookups that found nothing, or an expired entry
+	Evictions uint64 // entries dropped to stay within the size cap
+	Entries   int
+}
+
+// identityCache is an LRU cache of resolved identities whose entries also
+// expire after a TTL.
+type identityCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List // most recently used first
+	entries map[cacheKey]*list.Element
+	byPID   map[int32]cacheKey // the latest key stored for each PID
+	stats   CacheStats
+}
+
+func newIdentityCache(ttl time.Duration, size int) *identityCache {
+	return &identityCache{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[cacheKey]*list.Element),
+		byPID:   make(map[int32]cacheKey),
+	}
+}
+
+func (c *identityCache) get(key cacheKey) (domain.ProcessIdentity, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return domain.ProcessIdentity{}, false
+	}
+	e := el.Value.(*cacheEntry)
+	if !time.Now().Before(e.expiresAt) {
+		c.remove(el)
+		c.stats.Misses++
+		return domain.ProcessIdentity{}, false
+	}
+	c.order.MoveToFront(el)
+	c.stats.Hits++
+	return e.identity, true
+}
+
+func (c *identityCache) put(key cacheKey, id domain.ProcessIdentity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if old, ok := c.byPID[key.pid]; ok && old != key {
+		// The PID was reused; the old process's entry can never hit again.
+		if el, ok := c.entries[old]; ok {
+			c.remove(el)
+		}
+	}
+	e := &cacheEntry{key: key, identity: id, expiresAt: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[key] = c.order.PushFront(e)
+	}
+	c.byPID[key.pid] = key
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// invalidatePID drops the entry of whichever process last had pid.
+func (c *identityCache) invalidatePID(pid int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if key, ok := c.byPID[pid]; ok {
+		if el, ok := c.entries[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *identityCache) remove(el *list.Element) {
+	key := el.Value.(*cacheEntry).key
+	c.order.Remove(el)
+	delete(c.entries, key)
+	if c.byPID[key.pid] == key {
+		delete(c.byPID, key.pid)
+	}
+}
+
+func (c *identityCache) snapshot() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.order.Len()
+	return s
+}
@@ -0,0 +1,86 @@
+package process
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+func TestIdentityCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newIdentityCache(time.Minute, 2)
+	a, b, d := cacheKey{100, 1}, cacheKey{200, 1}, cacheKey{300, 1}
+	c.put(a, domain.ProcessIdentity{PID: 100, Name: "a"})
+	c.put(b, domain.ProcessIdentity{PID: 200, Name: "b"})
+	c.get(a) // a is now more recently used than b
+	c.put(d, domain.ProcessIdentity{PID: 300, Name: "d"})
+
+	if _, ok := c.get(b); ok {
+		t.Error("b should have been evicted")
+	}
+	if id, ok := c.get(a); !ok || id.Name != "a" {
+		t.Errorf("get(a) = %+v, %v; want it kept", id, ok)
+	}
+	want := CacheStats{Hits: 2, Misses: 1, Evictions: 1, Entries: 2}
+	if got := c.snapshot(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestIdentityCache_ReusedPIDMisses(t *testing.T) {
+	c := newIdentityCache(time.Minute, 10)
+	c.put(cacheKey{100, 1000}, domain.ProcessIdentity{PID: 100, Name: "old"})
+
+	if _, ok := c.get(cacheKey{100, 2000}); ok {
+		t.Fatal("a new process with a reused PID hit the old process's entry")
+	}
+	c.put(cacheKey{100, 2000}, domain.ProcessIdentity{PID: 100, Name: "new"})
+	if got := c.snapshot().Entries; got != 1 {
+		t.Errorf("%d entries, want the old process's dropped", got)
+	}
+
+	c.invalidatePID(100)
+	if _, ok := c.get(cacheKey{100, 2000}); ok {
+		t.Error("invalidatePID left the entry in place")
+	}
+}
+
+func TestIdentityCache_ExpiresAfterTTL(t *testing.T) {
+	c := newIdentityCache(10*time.Millisecond, 10)
+	c.put(cacheKey{100, 1}, domain.ProcessIdentity{PID: 100})
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.get(cacheKey{100, 1}); ok {
+		t.Error("expired entry was returned")
+	}
+	if got := c.snapshot(); got.Entries != 0 || got.Misses != 1 {
+		t.Errorf("stats = %+v, want the expired entry dropped and counted as a miss", got)
+	}
+}
+
+func TestResolver_EnrichHitsCacheForSameProcess(t *testing.T) {
+	r := NewResolverWithOptions(Options{CacheTTL: time.Minute})
+	var bindings []domain.PortBinding
+	for _, pid := range []int32{int32(os.Getpid()), int32(os.Getppid())} {
+		bindings = append(bindings, domain.PortBinding{PID: pid}, domain.PortBinding{PID: pid})
+	}
+
+	first, err := r.Enrich(context.Background(), slices.Clone(bindings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := r.Enrich(context.Background(), slices.Clone(bindings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range first {
+		if first[i].Process == nil || second[i].Process == nil || first[i].Process.Name != second[i].Process.Name {
+			t.Fatalf("binding %d: identities %+v and %+v differ", i, first[i].Process, second[i].Process)
+		}
+	}
+	if got := r.CacheStats(); got.Misses != 2 || got.Hits != 2 || got.Entries != 2 {
+		t.Errorf("stats = %+v, want each process resolved once and then served from the cache", got)
+	}
+}
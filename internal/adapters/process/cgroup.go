@@ -0,0 +1,149 @@
+package process
+
+import "strings"
+
+// parseContainer attributes a process to a container from the contents of
+// /proc/<pid>/cgroup. It understands the cgroup v1 ("N:controllers:/path")
+// and v2 ("0::/path") layouts written by the cgroupfs and systemd drivers:
+//
+//	/docker/<id>                                    docker (cgroupfs)
+//	/system.slice/docker-<id>.scope                 docker (systemd)
+//	/kubepods/burstable/pod<uid>/<id>               cri (cgroupfs)
+//	/kubepods.slice/.../cri-containerd-<id>.scope   containerd
+//	/kubepods.slice/.../crio-<id>.scope             cri-o
+//	/machine.slice/libpod-<id>.scope                podman
+//	/machine.slice/machine-<name>.scope             nspawn
+//
+// The innermost matching path component wins, so a container nested in a
+// pod is reported rather than the pod. It returns empty strings for host
+// processes.
+func parseContainer(cgroup string) (runtime, id string) {
+	for _, line := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		segs := strings.Split(parts[2], "/")
+		for i := len(segs) - 1; i >= 0; i-- {
+			parent := ""
+			if i > 0 {
+				parent = segs[i-1]
+			}
+			if runtime, id = matchCgroupSegment(segs[i], parent, parts[2]); id != "" {
+				return runtime, id
+			}
+		}
+	}
+	return "", ""
+}
+
+// parseSystemdUnit returns the innermost service or scope unit in the
+// systemd-managed hierarchy of /proc/<pid>/cgroup: the unified ("0::") line
+// on cgroup v2 and hybrid hosts, or the "name=systemd" line on v1. Units are
+// returned escaped, as systemctl expects them.
+func parseSystemdUnit(cgroup string) string {
+	for _, line := range strings.Split(cgroup, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || (parts[1] != "" && parts[1] != "name=systemd") {
+			continue
+		}
+		segs := strings.Split(parts[2], "/")
+		for i := len(segs) - 1; i >= 0; i-- {
+			if strings.HasSuffix(segs[i], ".service") || strings.HasSuffix(segs[i], ".scope") {
+				return segs[i]
+			}
+		}
+	}
+	return ""
+}
+
+var scopePrefixes = []struct {
+	prefix, runtime string
+}{
+	{"docker-", "docker"},
+	{"cri-containerd-", "containerd"},
+	{"crio-", "cri-o"},
+	{"libpod-", "podman"},
+}
+
+func matchCgroupSegment(seg, parent, path string) (string, string) {
+	name := strings.TrimSuffix(seg, ".scope")
+	// conmon, podman's per-container monitor, is not part of the container.
+	if strings.HasPrefix(name, "libpod-conmon-") || strings.HasPrefix(name, "crio-conmon-") {
+		return "", ""
+	}
+	for _, p := range scopePrefixes {
+		if id := strings.TrimPrefix(name, p.prefix); id != name && isContainerID(id) {
+			return p.runtime, id
+		}
+	}
+	if strings.HasSuffix(seg, ".scope") {
+		if m := strings.TrimPrefix(name, "machine-"); m != name {
+			return "nspawn", unescapeUnitName(m)
+		}
+	}
+	if isContainerID(seg) {
+		switch {
+		case parent == "docker" || parent == "moby":
+			return "docker", seg
+		case strings.Contains(path, "kubepods"):
+			return "cri", seg
+		default:
+			// ctr and nerdctl with the cgroupfs driver: /<namespace>/<id>
+			return "containerd", seg
+		}
+	}
+	return "", ""
+}
+
+// isContainerID reports whether s looks like a full container ID: 64 lower
+// case hex digits.
+func isContainerID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// unescapeUnitName reverses systemd's \xNN escaping of unit names, so
+// "machine-my\x2dbox.scope" yields "my-box".
+func unescapeUnitName(s string) string {
+	if !strings.Contains(s, `\x`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && s[i+1] == 'x' {
+			if c, ok := unhex(s[i+2 : i+4]); ok {
+				b.WriteByte(c)
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func unhex(s string) (byte, bool) {
+	var v byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			v = v<<4 | (c - '0')
+		case c >= 'a' && c <= 'f':
+			v = v<<4 | (c - 'a' + 10)
+		case c >= 'A' && c <= 'F':
+			v = v<<4 | (c - 'A' + 10)
+		default:
+			return 0, false
+		}
+	}
+	return v, true
+}
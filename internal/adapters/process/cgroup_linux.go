@@ -0,0 +1,18 @@
+//go:build linux
+
+package process
+
+import (
+	"os"
+	"strconv"
+)
+
+// readCgroup returns the contents of <procRoot>/<pid>/cgroup, or "" if it
+// cannot be read.
+func readCgroup(procRoot string, pid int32) string {
+	b, err := os.ReadFile(procRoot + "/" + strconv.Itoa(int(pid)) + "/cgroup")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
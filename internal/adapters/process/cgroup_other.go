@@ -0,0 +1,6 @@
+//go:build !linux
+
+package process
+
+// readCgroup returns "": cgroups only exist on Linux.
+func readCgroup(string, int32) string { return "" }
@@ -0,0 +1,55 @@
+package process
+
+import "testing"
+
+func TestParseContainer(t *testing.T) {
+	const id = "3f2a1b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7a8"
+	tests := []struct {
+		name        string
+		cgroup      string
+		wantRuntime string
+		wantID      string
+	}{
+		{"host v2", "0::/user.slice/user-1000.slice/session-2.scope\n", "", ""},
+		{"host v1", "12:pids:/system.slice/sshd.service\n1:name=systemd:/system.slice/sshd.service\n", "", ""},
+		{"docker v1 cgroupfs", "12:pids:/docker/" + id + "\n11:memory:/docker/" + id + "\n", "docker", id},
+		{"docker v2 systemd", "0::/system.slice/docker-" + id + ".scope\n", "docker", id},
+		{"kubernetes cgroupfs", "4:cpu:/kubepods/burstable/pod0b6c3a1e-8e4f-4d2b-9a1c-2f3e4d5c6b7a/" + id + "\n", "cri", id},
+		{"containerd cri v2", "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b6c3a1e.slice/cri-containerd-" + id + ".scope\n", "containerd", id},
+		{"cri-o", "0::/kubepods.slice/kubepods-besteffort.slice/kubepods-besteffort-podabc.slice/crio-" + id + ".scope\n", "cri-o", id},
+		{"podman rootful", "0::/machine.slice/libpod-" + id + ".scope\n", "podman", id},
+		{"podman rootless", "0::/user.slice/user-1000.slice/user@1000.service/user.slice/libpod-" + id + ".scope/container\n", "podman", id},
+		{"podman conmon", "0::/machine.slice/libpod-conmon-" + id + ".scope\n", "", ""},
+		{"nspawn", `0::/machine.slice/machine-build\x2dbox.scope/payload` + "\n", "nspawn", "build-box"},
+		{"containerd cgroupfs", "0::/default/" + id + "\n", "containerd", id},
+		{"malformed", "garbage\n\n", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt, cid := parseContainer(tt.cgroup)
+			if rt != tt.wantRuntime || cid != tt.wantID {
+				t.Errorf("parseContainer() = (%q, %q), want (%q, %q)", rt, cid, tt.wantRuntime, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestParseSystemdUnit(t *testing.T) {
+	tests := []struct {
+		name, cgroup, want string
+	}{
+		{"v2 service", "0::/system.slice/nginx.service\n", "nginx.service"},
+		{"v2 user service", "0::/user.slice/user-1000.slice/user@1000.service/app.slice/vite.service\n", "vite.service"},
+		{"v2 session", "0::/user.slice/user-1000.slice/session-4.scope\n", "session-4.scope"},
+		{"v1 name=systemd", "10:memory:/system.slice/ignored.service\n1:name=systemd:/system.slice/sshd.service\n", "sshd.service"},
+		{"init", "0::/init.scope\n", "init.scope"},
+		{"no systemd", "0::/\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSystemdUnit(tt.cgroup); got != tt.want {
+				t.Errorf("parseSystemdUnit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package process
+
+import "strconv"
+
+// processRootFS returns where the filesystem the process sees is reachable,
+// <procRoot>/<pid>/root, so its working directory resolves inside its own
+// mount namespace, such as a container's.
+func processRootFS(procRoot string, pid int32) string {
+	return procRoot + "/" + strconv.Itoa(int(pid)) + "/root"
+}
+
+// processExeFile returns <procRoot>/<pid>/exe, which opens the process's
+// executable even when it is in another mount namespace or was deleted.
+func processExeFile(procRoot string, pid int32) string {
+	return procRoot + "/" + strconv.Itoa(int(pid)) + "/exe"
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package process
+
+// processRootFS returns "": processes share the caller's filesystem view.
+func processRootFS(string, int32) string { return "" }
+
+// processExeFile returns "": the executable is opened by its path.
+func processExeFile(string, int32) string { return "" }
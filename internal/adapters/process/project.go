@@ -0,0 +1,39 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectMarkers are the entries whose presence makes a directory a project
+// root. .git is a directory in a checkout and a file in a worktree or
+// submodule.
+var ProjectMarkers = []string{".git", "go.mod", "package.json", "pyproject.toml"}
+
+// FindProjectRoot returns the nearest directory at or above dir holding one
+// of ProjectMarkers, or "" when there is none.
+func FindProjectRoot(dir string) string {
+	return findProjectRoot("", dir)
+}
+
+// findProjectRoot is FindProjectRoot for a dir that is a path inside fsRoot,
+// such as a process's root directory under procfs. The result is the path
+// as seen from inside fsRoot.
+func findProjectRoot(fsRoot, dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir = filepath.Clean(dir)
+	for {
+		for _, m := range ProjectMarkers {
+			if _, err := os.Lstat(filepath.Join(fsRoot, dir, m)); err == nil {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,47 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindProjectRoot(t *testing.T) {
+	fsRoot := t.TempDir()
+	mkdir := func(dir string) {
+		if err := os.MkdirAll(filepath.Join(fsRoot, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch := func(file string) {
+		if err := os.WriteFile(filepath.Join(fsRoot, file), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mkdir("/src/shop/.git")
+	mkdir("/src/shop/web/src")
+	touch("/src/shop/web/package.json")
+	mkdir("/src/shop/cmd/api")
+	mkdir("/src/tool")
+	touch("/src/tool/.git") // worktree
+	mkdir("/srv/www")
+
+	tests := []struct{ dir, want string }{
+		{"/src/shop", "/src/shop"},
+		{"/src/shop/cmd/api", "/src/shop"},
+		{"/src/shop/web/src", "/src/shop/web"},
+		{"/src/shop/web/", "/src/shop/web"},
+		{"/src/tool", "/src/tool"},
+		{"/srv/www", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := findProjectRoot(fsRoot, tt.dir); got != tt.want {
+			t.Errorf("findProjectRoot(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+
+	if got, want := FindProjectRoot(filepath.Join(fsRoot, "src/shop/cmd")), filepath.Join(fsRoot, "src/shop"); got != want {
+		t.Errorf("FindProjectRoot() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// redactedMark replaces masked secrets.
+const redactedMark = "***"
+
+// DefaultRedactionRules mask the secrets command lines most often carry.
+// Where a rule has capture groups only the groups are masked, so the option
+// or key naming the secret stays readable; otherwise the whole match is.
+var DefaultRedactionRules = []string{
+	// --password=s3cret, -token s3cret, --api-key=..., --client-secret ...
+	`(?i)(?:^|\s)--?[\w-]*(?:password|passwd|pass|pwd|secret|token|api[-_]?key|access[-_]?key|private[-_]?key|credentials?)(?:=|\s+)(\S+)`,
+	// GITHUB_TOKEN=..., DB_PASSWORD=..., ?access_token=...&page=2
+	`(?i)[\w.-]*(?:password|passwd|secret|token|api[-_]?key|access[-_]?key|private[-_]?key|credentials?)=([^\s&]+)`,
+	// AWS access key IDs
+	`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+	// user:password@ in URLs, e.g. postgres://app:s3cret@db/app
+	`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s/:@]*:([^\s/@]+)@`,
+	// Authorization: Bearer ...
+	`(?i)\bbearer\s+(\S+)`,
+}
+
+// Redactor masks secrets in command lines.
+type Redactor struct {
+	rules []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor applying DefaultRedactionRules and then
+// extra, which use the same capture-group convention.
+func NewRedactor(extra []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, expr := range append(append([]string(nil), DefaultRedactionRules...), extra...) {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %q: %w", expr, err)
+		}
+		r.rules = append(r.rules, re)
+	}
+	return r, nil
+}
+
+// Redact returns s with every secret a rule matches replaced by "***".
+func (r *Redactor) Redact(s string) string {
+	for _, re := range r.rules {
+		s = redactMatches(re, s)
+	}
+	return s
+}
+
+func redactMatches(re *regexp.Regexp, s string) string {
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	out := make([]byte, 0, len(s))
+	last := 0
+	for _, m := range matches {
+		spans := m[2:]
+		if len(spans) == 0 {
+			spans = m[:2]
+		}
+		for i := 0; i < len(spans); i += 2 {
+			start, end := spans[i], spans[i+1]
+			if start < 0 || start < last {
+				continue
+			}
+			out = append(out, s[last:start]...)
+			out = append(out, redactedMark...)
+			last = end
+		}
+	}
+	return string(append(out, s[last:]...))
+}
+
+// printable replaces control characters, such as newlines inside an
+// argument, with spaces so a command line stays on one line of output and
+// cannot drive the terminal.
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
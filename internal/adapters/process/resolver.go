@@ -2,120 +2,325 @@ package process
 
 import (
 	"context"
+	"os/user"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/shirou/gopsutil/v4/common"
 	"github.com/shirou/gopsutil/v4/process"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 )
 
-type cacheEntry struct {
-	identity  domain.ProcessIdentity
-	expiresAt time.Time
-}
+const (
+	// DefaultCacheTTL is how long a resolved identity is reused.
+	DefaultCacheTTL = 5 * time.Second
+
+	// DefaultCacheSize caps how many identities are cached; the least
+	// recently used are evicted beyond it.
+	DefaultCacheSize = 1024
+
+	// maxResolveWorkers bounds how many processes are resolved at once.
+	maxResolveWorkers = 8
+)
 
 // Resolver enriches port bindings with process metadata via gopsutil.
 type Resolver struct {
-	mu    sync.RWMutex
-	cache map[int32]*cacheEntry
-	ttl   time.Duration
+	mu       sync.RWMutex
+	cache    *identityCache
+	ttl      time.Duration
+	procRoot string
+	cmdline  bool
+	ancestry bool
+	redactor *Redactor
+	apps     []AppDetector
+
+	// resource usage, sampled on every Enrich rather than cached
+	usage      bool
+	cpuWindow  time.Duration
+	cpuSamples map[int32]cpuSample
+
+	// socket units systemd holds for activation, refreshed every ttl
+	units        []socketUnit
+	unitsExpires time.Time
+}
+
+// Options configures a Resolver.
+type Options struct {
+	// ProcRoot is where procfs is mounted, "/proc" when empty.
+	ProcRoot string
+
+	// Cmdline fills in ProcessIdentity.Cmdline, masked by Redactor or, when
+	// that is nil, by DefaultRedactionRules. Off by default, since few
+	// outputs show it. App labels are masked the same way.
+	Cmdline  bool
+	Redactor *Redactor
+
+	// AppDetectors derive ProcessIdentity.AppLabel, DefaultAppDetectors
+	// when nil. The first to recognise a process names it.
+	AppDetectors []AppDetector
+
+	// Ancestry fills in ProcessIdentity.Ancestors.
+	Ancestry bool
+
+	// Usage fills in ProcessIdentity.Usage, freshly on every Enrich. CPU
+	// usage is measured between consecutive Enrich calls and, when
+	// CPUWindow is set, over that long for processes not seen before.
+	Usage     bool
+	CPUWindow time.Duration
+
+	// CacheTTL and CacheSize bound the identity cache, DefaultCacheTTL and
+	// DefaultCacheSize when zero. Identities are cached by PID and create
+	// time, so a reused PID is never mistaken for the process it replaced.
+	CacheTTL  time.Duration
+	CacheSize int
 }
 
 func NewResolver() *Resolver {
-	return &Resolver{
-		cache: make(map[int32]*cacheEntry),
-		ttl:   5 * time.Second,
+	return NewResolverWithOptions(Options{})
+}
+
+// NewResolverWithOptions creates a Resolver configured with opts.
+func NewResolverWithOptions(opts Options) *Resolver {
+	r := &Resolver{
+		ttl:      DefaultCacheTTL,
+		procRoot: "/proc",
+		cmdline:  opts.Cmdline,
+		ancestry: opts.Ancestry,
+		redactor: opts.Redactor,
+		apps:     opts.AppDetectors,
+
+		usage:      opts.Usage,
+		cpuWindow:  opts.CPUWindow,
+		cpuSamples: make(map[int32]cpuSample),
+	}
+	if opts.ProcRoot != "" {
+		r.procRoot = opts.ProcRoot
+	}
+	if opts.CacheTTL > 0 {
+		r.ttl = opts.CacheTTL
+	}
+	size := DefaultCacheSize
+	if opts.CacheSize > 0 {
+		size = opts.CacheSize
 	}
+	r.cache = newIdentityCache(r.ttl, size)
+	if r.redactor == nil {
+		r.redactor, _ = NewRedactor(nil)
+	}
+	if r.apps == nil {
+		r.apps = DefaultAppDetectors
+	}
+	return r
 }
 
 func (r *Resolver) Enrich(ctx context.Context, bindings []domain.PortBinding) ([]domain.PortBinding, error) {
 	pids := uniquePIDs(bindings)
 	identities := r.batchResolve(ctx, pids)
+	if r.usage {
+		r.sampleUsage(ctx, identities)
+	}
 
 	for i := range bindings {
 		if id, ok := identities[bindings[i].PID]; ok {
 			cp := id
 			bindings[i].Process = &cp
 		}
+		for j := range bindings[i].Owners {
+			if id, ok := identities[bindings[i].Owners[j].PID]; ok {
+				cp := id
+				bindings[i].Owners[j].Process = &cp
+			}
+		}
+	}
+
+	// The socket's own UID names an owner even when no process was found.
+	users := make(map[uint32]string)
+	for i := range bindings {
+		if bindings[i].HasUID {
+			bindings[i].Username = lookupUsername(users, bindings[i].UID)
+		}
+	}
+
+	var units []socketUnit
+	for i := range bindings {
+		if !heldBySystemd(&bindings[i]) {
+			continue
+		}
+		if units == nil {
+			units = r.socketUnits(ctx)
+		}
+		bindings[i].SocketUnit = matchSocketUnit(units, &bindings[i])
 	}
 	return bindings, nil
 }
 
+// socketUnits returns the cached `systemctl list-sockets` rows. A failed
+// lookup caches an empty list, so sockets are simply left unattributed.
+func (r *Resolver) socketUnits(ctx context.Context) []socketUnit {
+	r.mu.RLock()
+	if time.Now().Before(r.unitsExpires) {
+		defer r.mu.RUnlock()
+		return r.units
+	}
+	r.mu.RUnlock()
+
+	units, err := listSocketUnits(ctx)
+	if err != nil {
+		units = []socketUnit{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.units, r.unitsExpires = units, time.Now().Add(r.ttl)
+	return units
+}
+
+// batchResolve resolves pids on up to maxResolveWorkers goroutines. PIDs
+// left when ctx is done are missing from the result.
 func (r *Resolver) batchResolve(ctx context.Context, pids []int32) map[int32]domain.ProcessIdentity {
 	result := make(map[int32]domain.ProcessIdentity, len(pids))
+	var mu sync.Mutex
+	work := make(chan int32)
+	var wg sync.WaitGroup
+	for range min(len(pids), maxResolveWorkers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pid := range work {
+				id := r.resolveCached(ctx, pid)
+				mu.Lock()
+				result[pid] = id
+				mu.Unlock()
+			}
+		}()
+	}
+feed:
 	for _, pid := range pids {
-		if cached := r.getCache(pid); cached != nil {
-			result[pid] = *cached
-			continue
-		}
-
 		select {
+		case work <- pid:
 		case <-ctx.Done():
-			return result
-		default:
+			break feed
 		}
-
-		id := resolveOne(pid)
-		result[pid] = id
-		r.setCache(pid, id)
 	}
+	close(work)
+	wg.Wait()
 	return result
 }
 
-func resolveOne(pid int32) domain.ProcessIdentity {
-	id := domain.ProcessIdentity{PID: pid}
-	p, err := process.NewProcess(pid)
+// resolveCached returns pid's identity from the cache or, on a miss,
+// resolves and caches it.
+func (r *Resolver) resolveCached(ctx context.Context, pid int32) domain.ProcessIdentity {
+	ctx = r.procContext(ctx)
+	p, err := process.NewProcessWithContext(ctx, pid)
 	if err != nil {
-		id.PermissionDenied = true
+		return domain.ProcessIdentity{PID: pid, PermissionDenied: true}
+	}
+	createMs, err := p.CreateTimeWithContext(ctx)
+	if err != nil {
+		// Without a create time a cached identity could be that of an
+		// earlier process with the same PID.
+		return r.resolveOne(ctx, p)
+	}
+	key := cacheKey{pid: pid, createMs: createMs}
+	if id, ok := r.cache.get(key); ok {
 		return id
 	}
+	id := r.resolveOne(ctx, p)
+	r.cache.put(key, id)
+	return id
+}
 
-	if ct, err := p.CreateTime(); err == nil {
+// procContext makes gopsutil read procfs wherever the context's HOST_PROC
+// points.
+func (r *Resolver) procContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, common.EnvKey, common.EnvMap{common.HostProcEnvKey: r.procRoot})
+}
+
+// resolveOne reads p's identity. ctx must come from procContext.
+func (r *Resolver) resolveOne(ctx context.Context, p *process.Process) domain.ProcessIdentity {
+	pid := p.Pid
+	id := domain.ProcessIdentity{PID: pid}
+	if ct, err := p.CreateTimeWithContext(ctx); err == nil {
 		id.CreateTimeMs = ct
 	}
-	if name, err := p.Name(); err == nil {
+	if ppid, err := p.PpidWithContext(ctx); err == nil {
+		id.PPID = ppid
+	}
+	if name, err := p.NameWithContext(ctx); err == nil {
 		id.Name = name
 	}
-	if exe, err := p.Exe(); err == nil {
+	if exe, err := p.ExeWithContext(ctx); err == nil {
 		id.Exe = exe
 	}
-	if user, err := p.Username(); err == nil {
+	if user, err := p.UsernameWithContext(ctx); err == nil {
 		id.Username = user
 	}
-	// Cmdline is expensive — lazy enrichment: only populate on explicit request
+	cgroup := readCgroup(r.procRoot, pid)
+	id.ContainerRuntime, id.ContainerID = parseContainer(cgroup)
+	if !id.InContainer() {
+		id.SystemdUnit = parseSystemdUnit(cgroup)
+	}
+	if cwd, err := p.CwdWithContext(ctx); err == nil {
+		id.Cwd = cwd
+		id.ProjectRoot = findProjectRoot(processRootFS(r.procRoot, pid), cwd)
+	}
+	// The app label is derived from argv for every process; the full
+	// command line is only kept on explicit request.
+	if argv, err := p.CmdlineSliceWithContext(ctx); err == nil && len(argv) > 0 {
+		app := &AppProcess{Argv: argv, Exe: id.Exe, ExeFile: processExeFile(r.procRoot, pid)}
+		id.AppLabel = r.redactor.Redact(printable(detectApp(r.apps, app)))
+		if r.cmdline {
+			id.Cmdline = r.redactor.Redact(printable(strings.Join(argv, " ")))
+		}
+	}
+	if r.ancestry {
+		for _, a := range r.ancestorsFrom(ctx, pid, id.PPID) {
+			id.Ancestors = append(id.Ancestors, domain.ProcessRef{PID: a.PID, Name: a.Name})
+		}
+	}
 	return id
 }
 
-func (r *Resolver) getCache(pid int32) *domain.ProcessIdentity {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	if e, ok := r.cache[pid]; ok && time.Now().Before(e.expiresAt) {
-		return &e.identity
+// lookupUsername resolves uid, memoizing results (including failures, which
+// fall back to the numeric UID) in cache.
+func lookupUsername(cache map[uint32]string, uid uint32) string {
+	if name, ok := cache[uid]; ok {
+		return name
 	}
-	return nil
-}
-
-func (r *Resolver) setCache(pid int32, id domain.ProcessIdentity) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	r.cache[pid] = &cacheEntry{identity: id, expiresAt: time.Now().Add(r.ttl)}
+	name := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	cache[uid] = name
+	return name
 }
 
 // InvalidatePID removes a PID from the cache, forcing a fresh lookup on next Enrich.
 func (r *Resolver) InvalidatePID(pid int32) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.cache, pid)
+	r.cache.invalidatePID(pid)
+}
+
+// CacheTTL returns how long resolved identities are reused.
+func (r *Resolver) CacheTTL() time.Duration {
+	return r.ttl
+}
+
+// CacheStats returns the identity cache's counters.
+func (r *Resolver) CacheStats() CacheStats {
+	return r.cache.snapshot()
 }
 
 func uniquePIDs(bindings []domain.PortBinding) []int32 {
 	seen := make(map[int32]bool)
 	var pids []int32
 	for _, b := range bindings {
-		if b.PID > 0 && !seen[b.PID] {
-			seen[b.PID] = true
-			pids = append(pids, b.PID)
+		for _, pid := range b.OwnerPIDs() {
+			if pid > 0 && !seen[pid] {
+				seen[pid] = true
+				pids = append(pids, pid)
+			}
 		}
 	}
 	return pids
@@ -0,0 +1,92 @@
+package process
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// socketUnit is one row of `systemctl list-sockets`: a socket systemd
+// listens on for socket activation and the .socket unit that declared it.
+type socketUnit struct {
+	listen string // "[::]:22", "0.0.0.0:8080", "/run/foo.sock"
+	typ    string // Stream, Datagram, SequentialPacket, ...
+	unit   string
+}
+
+// heldBySystemd reports whether PID 1 running systemd holds the socket,
+// which is how socket-activated listeners appear before (and, with
+// Accept=no, after) their service starts.
+func heldBySystemd(b *domain.PortBinding) bool {
+	for _, o := range b.Owners {
+		if o.PID == 1 && o.Process != nil && o.Process.Name == "systemd" {
+			return true
+		}
+	}
+	return b.PID == 1 && b.Process != nil && b.Process.Name == "systemd"
+}
+
+// listSocketUnits asks systemd which sockets it holds for .socket units.
+func listSocketUnits(ctx context.Context) ([]socketUnit, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "systemctl", "list-sockets",
+		"--all", "--full", "--show-types", "--no-legend", "--no-pager").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseListSockets(string(out)), nil
+}
+
+// parseListSockets parses `systemctl list-sockets --show-types --no-legend`,
+// whose rows are "LISTEN TYPE UNIT ACTIVATES".
+func parseListSockets(out string) []socketUnit {
+	var units []socketUnit
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasSuffix(fields[2], ".socket") {
+			continue
+		}
+		units = append(units, socketUnit{listen: fields[0], typ: fields[1], unit: fields[2]})
+	}
+	return units
+}
+
+// matchSocketUnit finds the .socket unit whose listen address is b.
+func matchSocketUnit(units []socketUnit, b *domain.PortBinding) string {
+	for _, u := range units {
+		if b.Protocol == domain.Unix {
+			if u.listen == b.Path {
+				return u.unit
+			}
+			continue
+		}
+		if (b.Protocol == domain.TCP && u.typ != "Stream") || (b.Protocol == domain.UDP && u.typ != "Datagram") {
+			continue
+		}
+		host, port, err := net.SplitHostPort(u.listen)
+		if err != nil {
+			host, port = "", u.listen // port only, e.g. ListenStream=8080
+		}
+		if port != strconv.Itoa(int(b.LocalPort)) {
+			continue
+		}
+		if host == "" || host == "*" {
+			return u.unit
+		}
+		if i := strings.IndexByte(host, '%'); i >= 0 {
+			host = host[:i]
+		}
+		// An address we cannot parse matches nothing rather than everything.
+		ip := net.ParseIP(host)
+		if ip != nil && (ip.IsUnspecified() || domain.NormalizeIP(ip).Equal(b.LocalIP)) {
+			return u.unit
+		}
+	}
+	return ""
+}
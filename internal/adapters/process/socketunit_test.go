@@ -0,0 +1,44 @@
+package process
+
+import (
+	"net"
+	"testing"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+func TestMatchSocketUnit(t *testing.T) {
+	units := parseListSockets(`/run/dbus/system_bus_socket Stream   dbus.socket    dbus.service
+[::]:22                     Stream   sshd.socket    sshd.service
+127.0.0.1:5353              Datagram mdns.socket    mdns.service
+0.0.0.0:8080                Stream   api.socket
+9100                        Stream   metrics.socket
+localhost:7000              Stream   bogus.socket
+`)
+	if len(units) != 6 {
+		t.Fatalf("expected 6 socket units, got %d: %+v", len(units), units)
+	}
+
+	tests := []struct {
+		name string
+		b    domain.PortBinding
+		want string
+	}{
+		{"dual-stack ssh", domain.PortBinding{Protocol: domain.TCP, LocalIP: net.IPv6unspecified, LocalPort: 22}, "sshd.socket"},
+		{"udp", domain.PortBinding{Protocol: domain.UDP, LocalIP: net.IPv4(127, 0, 0, 1).To4(), LocalPort: 5353}, "mdns.socket"},
+		{"tcp is not udp", domain.PortBinding{Protocol: domain.TCP, LocalIP: net.IPv4(127, 0, 0, 1).To4(), LocalPort: 5353}, ""},
+		{"specific address", domain.PortBinding{Protocol: domain.TCP, LocalIP: net.IPv4(10, 0, 0, 1).To4(), LocalPort: 8080}, "api.socket"},
+		{"unix path", domain.PortBinding{Protocol: domain.Unix, Path: "/run/dbus/system_bus_socket"}, "dbus.socket"},
+		{"port only", domain.PortBinding{Protocol: domain.TCP, LocalIP: net.IPv4(10, 0, 0, 1).To4(), LocalPort: 9100}, "metrics.socket"},
+		{"unparseable host", domain.PortBinding{Protocol: domain.TCP, LocalIP: net.IPv4(127, 0, 0, 1).To4(), LocalPort: 7000}, ""},
+		{"specific unit, wildcard binding", domain.PortBinding{Protocol: domain.UDP, LocalIP: net.IPv4zero, LocalPort: 5353}, ""},
+		{"unknown port", domain.PortBinding{Protocol: domain.TCP, LocalIP: net.IPv4zero, LocalPort: 9999}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchSocketUnit(units, &tt.b); got != tt.want {
+				t.Errorf("matchSocketUnit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
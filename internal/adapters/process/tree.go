@@ -0,0 +1,92 @@
+package process
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+// maxAncestors bounds an ancestry walk, which could otherwise cycle through
+// PIDs reused while it runs.
+const maxAncestors = 32
+
+// Ancestors implements ports.ProcessTree. The walk ends early at a parent
+// that has exited or cannot be read.
+func (r *Resolver) Ancestors(ctx context.Context, pid int32) ([]domain.ProcessIdentity, error) {
+	self, err := r.brief(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	return r.ancestorsFrom(ctx, pid, self.PPID), nil
+}
+
+// Descendants implements ports.ProcessTree by grouping the whole process
+// table by parent.
+func (r *Resolver) Descendants(ctx context.Context, pid int32) ([]domain.ProcessIdentity, error) {
+	pids, err := process.PidsWithContext(r.procContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int32][]domain.ProcessIdentity)
+	for _, p := range pids {
+		if id, err := r.brief(ctx, p); err == nil && id.PPID != p {
+			children[id.PPID] = append(children[id.PPID], id)
+		}
+	}
+
+	var out []domain.ProcessIdentity
+	queue := []int32{pid}
+	seen := map[int32]bool{pid: true}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, c := range children[parent] {
+			if seen[c.PID] {
+				continue
+			}
+			seen[c.PID] = true
+			out = append(out, c)
+			queue = append(queue, c.PID)
+		}
+	}
+	return out, nil
+}
+
+// ancestorsFrom walks up from ppid, the parent of pid.
+func (r *Resolver) ancestorsFrom(ctx context.Context, pid, ppid int32) []domain.ProcessIdentity {
+	var out []domain.ProcessIdentity
+	seen := map[int32]bool{pid: true}
+	for ppid > 1 && len(out) < maxAncestors && !seen[ppid] {
+		seen[ppid] = true
+		id, err := r.brief(ctx, ppid)
+		if err != nil {
+			break
+		}
+		out = append(out, id)
+		ppid = id.PPID
+	}
+	return out
+}
+
+// brief looks up just enough of pid to place it in the process tree and
+// revalidate it later.
+func (r *Resolver) brief(ctx context.Context, pid int32) (domain.ProcessIdentity, error) {
+	ctx = r.procContext(ctx)
+	id := domain.ProcessIdentity{PID: pid}
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return id, err
+	}
+	if ct, err := p.CreateTimeWithContext(ctx); err == nil {
+		id.CreateTimeMs = ct
+	}
+	if ppid, err := p.PpidWithContext(ctx); err == nil {
+		id.PPID = ppid
+	}
+	if name, err := p.NameWithContext(ctx); err == nil {
+		id.Name = name
+	}
+	return id, nil
+}
@@ -0,0 +1,42 @@
+package process
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestResolver_AncestorsAndDescendants(t *testing.T) {
+	r := NewResolver()
+	ctx := context.Background()
+
+	if ppid := int32(os.Getppid()); ppid > 1 {
+		ancestors, err := r.Ancestors(ctx, int32(os.Getpid()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ancestors) == 0 || ancestors[0].PID != ppid {
+			t.Errorf("Ancestors(self) = %+v, want the parent %d first", ancestors, ppid)
+		}
+	}
+
+	cmd := exec.Command("sleep", "5")
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer func() { cmd.Process.Kill(); cmd.Wait() }()
+	below, err := r.Descendants(ctx, int32(os.Getpid()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, id := range below {
+		if id.PID == int32(cmd.Process.Pid) {
+			found = id.CreateTimeMs != 0
+		}
+	}
+	if !found {
+		t.Errorf("Descendants(self) = %+v, want the child %d with its create time", below, cmd.Process.Pid)
+	}
+}
@@ -0,0 +1,157 @@
+package process
+
+import (
+	"context"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+const (
+	// DefaultCPUWindow is how long a process seen for the first time is
+	// watched to measure its CPU usage.
+	DefaultCPUWindow = 500 * time.Millisecond
+
+	// minCPUInterval is the shortest interval a CPU percentage is computed
+	// over. A process sampled again sooner, e.g. for a single socket event
+	// right after a refresh, keeps its previous figure.
+	minCPUInterval = 100 * time.Millisecond
+
+	// sampleExpiry is how long the CPU sample of a process that is no
+	// longer being resolved is kept.
+	sampleExpiry = time.Minute
+)
+
+// cpuSample is the total CPU time a process had used at a moment, the
+// baseline for its next CPU percentage.
+type cpuSample struct {
+	createMs int64
+	cpu      float64 // user plus system seconds
+	at       time.Time
+	percent  float64 // computed at this sample, -1 if it was the first
+}
+
+// SampleUsage implements ports.UsageSampler. It never waits for a CPU
+// window.
+func (r *Resolver) SampleUsage(ctx context.Context, pids []int32) map[int32]*domain.ProcessUsage {
+	ctx = r.procContext(ctx)
+	targets := make(map[int32]usageTarget, len(pids))
+	for _, pid := range pids {
+		p, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue
+		}
+		createMs, _ := p.CreateTimeWithContext(ctx)
+		targets[pid] = usageTarget{proc: p, createMs: createMs}
+	}
+	return r.measure(ctx, targets, 0)
+}
+
+// sampleUsage fills in the Usage of every identity in ids, waiting out the
+// CPU window for processes not sampled before.
+func (r *Resolver) sampleUsage(ctx context.Context, ids map[int32]domain.ProcessIdentity) {
+	ctx = r.procContext(ctx)
+	targets := make(map[int32]usageTarget, len(ids))
+	for pid, id := range ids {
+		if id.PermissionDenied {
+			continue
+		}
+		if p, err := process.NewProcessWithContext(ctx, pid); err == nil {
+			targets[pid] = usageTarget{proc: p, createMs: id.CreateTimeMs}
+		}
+	}
+	for pid, u := range r.measure(ctx, targets, r.cpuWindow) {
+		id := ids[pid]
+		id.Usage = u
+		ids[pid] = id
+	}
+}
+
+// usageTarget is a process to measure and its create time, which tells
+// its CPU samples apart from those of an earlier process with its PID.
+type usageTarget struct {
+	proc     *process.Process
+	createMs int64
+}
+
+// measure reads the usage of targets. CPU percentages are measured against
+// each process's previous sample, taken by an earlier call (a watch tick)
+// or, with a window set, taken up front for processes without one, waiting
+// the window once for the whole batch.
+func (r *Resolver) measure(ctx context.Context, targets map[int32]usageTarget, window time.Duration) map[int32]*domain.ProcessUsage {
+	if window > 0 {
+		baseline := false
+		for pid, t := range targets {
+			if !r.hasCPUSample(pid, t.createMs) {
+				r.cpuPercent(ctx, t.proc, t.createMs)
+				baseline = true
+			}
+		}
+		if baseline {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(window):
+			}
+		}
+	}
+
+	usage := make(map[int32]*domain.ProcessUsage, len(targets))
+	for pid, t := range targets {
+		u := &domain.ProcessUsage{CPUPercent: r.cpuPercent(ctx, t.proc, t.createMs), FDs: -1}
+		if mem, err := t.proc.MemoryInfoWithContext(ctx); err == nil {
+			u.RSS = mem.RSS
+		}
+		if n, err := t.proc.NumFDsWithContext(ctx); err == nil {
+			u.FDs = n
+		}
+		usage[pid] = u
+	}
+	r.pruneCPUSamples()
+	return usage
+}
+
+func (r *Resolver) hasCPUSample(pid int32, createMs int64) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.cpuSamples[pid]
+	return ok && s.createMs == createMs
+}
+
+// cpuPercent records a CPU sample for p and returns its CPU usage since the
+// previous one, or -1 when there is none: the process is new, or its PID
+// now belongs to a different process.
+func (r *Resolver) cpuPercent(ctx context.Context, p *process.Process, createMs int64) float64 {
+	times, err := p.TimesWithContext(ctx)
+	if err != nil {
+		return -1
+	}
+	total, now := times.User+times.System, time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.cpuSamples[p.Pid]
+	if !ok || prev.createMs != createMs {
+		r.cpuSamples[p.Pid] = cpuSample{createMs: createMs, cpu: total, at: now, percent: -1}
+		return -1
+	}
+	elapsed := now.Sub(prev.at)
+	if elapsed < minCPUInterval {
+		return prev.percent
+	}
+	percent := max(0, 100*(total-prev.cpu)/elapsed.Seconds())
+	r.cpuSamples[p.Pid] = cpuSample{createMs: createMs, cpu: total, at: now, percent: percent}
+	return percent
+}
+
+func (r *Resolver) pruneCPUSamples() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for pid, s := range r.cpuSamples {
+		if time.Since(s.at) > sampleExpiry {
+			delete(r.cpuSamples, pid)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package process
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+)
+
+func TestResolver_UsageMeasuresCPUOverWindow(t *testing.T) {
+	r := NewResolverWithOptions(Options{Usage: true, CPUWindow: 200 * time.Millisecond})
+	self := []domain.PortBinding{{PID: int32(os.Getpid())}}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+		}
+	}()
+
+	start := time.Now()
+	got, err := r.Enrich(context.Background(), self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 200*time.Millisecond {
+		t.Errorf("first Enrich took %v, want it to wait out the CPU window", waited)
+	}
+	u := got[0].Process.Usage
+	if u == nil || !u.HasCPU() || u.CPUPercent == 0 || u.RSS == 0 {
+		t.Fatalf("Usage = %+v, want CPU and RSS of a busy process", u)
+	}
+	if runtime.GOOS == "linux" && u.FDs <= 0 {
+		t.Errorf("FDs = %d, want the test binary's open files", u.FDs)
+	}
+
+	start = time.Now()
+	if _, err := r.Enrich(context.Background(), []domain.PortBinding{{PID: int32(os.Getpid())}}); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited >= 200*time.Millisecond {
+		t.Errorf("second Enrich took %v, want it measured against the first sample", waited)
+	}
+}
+
+func TestResolver_SampleUsageMeasuresCPUBetweenCalls(t *testing.T) {
+	r := NewResolver()
+	pid := int32(os.Getpid())
+
+	first := r.SampleUsage(context.Background(), []int32{pid})
+	if u := first[pid]; u == nil || u.HasCPU() || u.RSS == 0 {
+		t.Fatalf("first sample = %+v, want RSS but no CPU figure yet", u)
+	}
+	time.Sleep(2 * minCPUInterval)
+	if u := r.SampleUsage(context.Background(), []int32{pid})[pid]; u == nil || !u.HasCPU() {
+		t.Errorf("second sample = %+v, want CPU measured since the first", u)
+	}
+}
@@ -3,8 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -15,6 +18,10 @@ type Config struct {
 	ColorTheme       string   `yaml:"color_theme"`
 	CriticalDenylist []string `yaml:"critical_process_denylist"`
 	DefaultColumns   []string `yaml:"default_columns"`
+	ProcRoot         string   `yaml:"proc_root"`
+	SocketBackend    string   `yaml:"socket_backend"`
+	RedactPatterns   []string `yaml:"redact_patterns"`
+	CacheTTL         string   `yaml:"process_cache_ttl"`
 }
 
 // DefaultConfig returns the default configuration.
@@ -26,6 +33,9 @@ func DefaultConfig() *Config {
 			"systemd", "launchd", "init", "csrss.exe", "smss.exe", "wininit.exe",
 		},
 		DefaultColumns: []string{"proto", "local_addr", "pid", "process", "user", "state"},
+		ProcRoot:       "/proc",
+		SocketBackend:  "auto",
+		CacheTTL:       "5s",
 	}
 }
 
@@ -66,6 +76,21 @@ func applyEnv(cfg *Config) {
 	if v := os.Getenv("PORTHOG_DENYLIST"); v != "" {
 		cfg.CriticalDenylist = strings.Split(v, ",")
 	}
+	if v := os.Getenv("PORTHOG_PROC_ROOT"); v != "" {
+		cfg.ProcRoot = v
+	}
+	if v := os.Getenv("PORTHOG_BACKEND"); v != "" {
+		cfg.SocketBackend = v
+	}
+	if v := os.Getenv("PORTHOG_CACHE_TTL"); v != "" {
+		cfg.CacheTTL = v
+	}
+}
+
+// ProcessCacheTTL returns CacheTTL parsed. Validate has checked it.
+func (c *Config) ProcessCacheTTL() time.Duration {
+	d, _ := time.ParseDuration(c.CacheTTL)
+	return d
 }
 
 // Validate checks config values are valid.
@@ -78,5 +103,22 @@ func (c *Config) Validate() error {
 	if !validThemes[c.ColorTheme] {
 		return fmt.Errorf("invalid color_theme: %q (must be auto|always|never)", c.ColorTheme)
 	}
+	validBackends := map[string]bool{"auto": true, "netlink": true, "proc": true}
+	if !validBackends[c.SocketBackend] {
+		return fmt.Errorf("invalid socket_backend: %q (must be auto|netlink|proc)", c.SocketBackend)
+	}
+	// procfs paths are Linux paths, so they are checked as such on every
+	// OS; filepath.IsAbs would reject the default "/proc" on Windows.
+	if !path.IsAbs(c.ProcRoot) {
+		return fmt.Errorf("invalid proc_root: %q (must be an absolute path)", c.ProcRoot)
+	}
+	if d, err := time.ParseDuration(c.CacheTTL); err != nil || d <= 0 {
+		return fmt.Errorf("invalid process_cache_ttl: %q (must be a positive duration, e.g. 5s)", c.CacheTTL)
+	}
+	for _, p := range c.RedactPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid redact_patterns entry %q: %w", p, err)
+		}
+	}
 	return nil
 }
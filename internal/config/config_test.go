@@ -0,0 +1,30 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestDefaultConfig_Validates(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("default config invalid on %s: %v", runtime.GOOS, err)
+	}
+}
+
+func TestValidate_ProcRoot(t *testing.T) {
+	for _, tt := range []struct {
+		root  string
+		valid bool
+	}{
+		{"/proc", true},
+		{"/host/proc", true},
+		{"proc", false},
+		{"", false},
+	} {
+		cfg := DefaultConfig()
+		cfg.ProcRoot = tt.root
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("proc_root %q: Validate() = %v, want valid %v", tt.root, err, tt.valid)
+		}
+	}
+}
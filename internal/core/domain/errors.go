@@ -13,6 +13,12 @@ var (
 	ErrNoFreePort        = errors.New("no free port available in the specified range")
 	ErrInvalidPort       = errors.New("invalid port number")
 	ErrInvalidRange      = errors.New("invalid port range")
+	ErrInvalidState      = errors.New("invalid socket state")
+	ErrSharedSocket      = errors.New("socket is shared by multiple processes")
+	ErrSocketActivated   = errors.New("socket-activated service")
+	ErrKernelSocket      = errors.New("socket is owned by the kernel")
+	ErrUnknownInterface  = errors.New("unknown network interface")
+	ErrNoSupervisor      = errors.New("no supervising parent process")
 )
 
 // PartialResult wraps a result that may be incomplete due to permission restrictions.
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"path/filepath"
+	"strings"
+)
+
 // PortRange represents an inclusive port range.
 type PortRange struct {
 	Start uint16
@@ -19,13 +24,32 @@ func (r PortRange) Valid() bool {
 // Filter specifies criteria for filtering port bindings.
 type Filter struct {
 	Protocols []Protocol
+	Families  []AddressFamily
 	Ports     []uint16
 	PortRange *PortRange
 	PIDs      []int32
 	States    []SocketState
+	Paths     []string
+
+	// Interfaces keeps sockets reachable through these network interfaces:
+	// those bound to them or to their addresses, and wildcard listeners.
+	Interfaces []string
+
+	// Process-level criteria. They need resolved process identities, so
+	// enumerators ignore them and they are checked by MatchesProcess.
+	Containers []string // container ID prefixes or runtime names
+	Projects   []string // absolute directories the process must run in or below
+	Apps       []string // runtimes ("java") or parts of app labels ("uvicorn")
 }
 
-// Matches returns true if a PortBinding satisfies this filter.
+// HasProcessCriteria reports whether the filter has criteria that can only
+// be checked by MatchesProcess.
+func (f *Filter) HasProcessCriteria() bool {
+	return f != nil && (len(f.Containers) > 0 || len(f.Projects) > 0 || len(f.Apps) > 0)
+}
+
+// Matches returns true if a PortBinding satisfies the socket-level criteria
+// of this filter.
 func (f *Filter) Matches(pb *PortBinding) bool {
 	if f == nil {
 		return true
@@ -33,21 +57,127 @@ func (f *Filter) Matches(pb *PortBinding) bool {
 	if len(f.Protocols) > 0 && !containsProtocol(f.Protocols, pb.Protocol) {
 		return false
 	}
+	if len(f.Families) > 0 && !containsFamily(f.Families, pb.Family) {
+		return false
+	}
 	if len(f.Ports) > 0 && !containsPort(f.Ports, pb.LocalPort) {
 		return false
 	}
 	if f.PortRange != nil && !f.PortRange.Contains(pb.LocalPort) {
 		return false
 	}
-	if len(f.PIDs) > 0 && !containsPID(f.PIDs, pb.PID) {
+	if len(f.PIDs) > 0 && !containsAnyPID(f.PIDs, pb.OwnerPIDs()) {
 		return false
 	}
 	if len(f.States) > 0 && !containsState(f.States, pb.State) {
 		return false
 	}
+	if len(f.Paths) > 0 && !containsPath(f.Paths, pb.Path) {
+		return false
+	}
+	if len(f.Interfaces) > 0 && !matchesInterface(f.Interfaces, pb) {
+		return false
+	}
+	return true
+}
+
+func matchesInterface(ifaces []string, pb *PortBinding) bool {
+	if pb.IsWildcard() {
+		return true
+	}
+	for _, name := range ifaces {
+		if pb.Interface == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesProcess returns true if a PortBinding whose owners have been
+// resolved satisfies the process-level criteria of this filter. A binding
+// matches when any of its owners does.
+func (f *Filter) MatchesProcess(pb *PortBinding) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Containers) > 0 && !anyOwnerIdentity(pb, func(p *ProcessIdentity) bool {
+		return matchesContainer(f.Containers, p)
+	}) {
+		return false
+	}
+	if len(f.Projects) > 0 && !anyOwnerIdentity(pb, func(p *ProcessIdentity) bool {
+		return matchesProject(f.Projects, p)
+	}) {
+		return false
+	}
+	if len(f.Apps) > 0 && !anyOwnerIdentity(pb, func(p *ProcessIdentity) bool {
+		return matchesApp(f.Apps, p)
+	}) {
+		return false
+	}
 	return true
 }
 
+// anyOwnerIdentity reports whether fn holds for the identity of the primary
+// owner or of any other holder.
+func anyOwnerIdentity(pb *PortBinding, fn func(*ProcessIdentity) bool) bool {
+	if pb.Process != nil && fn(pb.Process) {
+		return true
+	}
+	for _, o := range pb.Owners {
+		if o.Process != nil && fn(o.Process) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesContainer accepts a runtime name ("docker") or a prefix of the
+// container ID, as accepted by `docker ps --filter id=`.
+func matchesContainer(s []string, p *ProcessIdentity) bool {
+	if !p.InContainer() {
+		return false
+	}
+	for _, c := range s {
+		if c == p.ContainerRuntime || strings.HasPrefix(p.ContainerID, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesProject accepts a process whose working directory is one of dirs
+// or lies below it.
+func matchesProject(dirs []string, p *ProcessIdentity) bool {
+	if p.Cwd == "" {
+		return false
+	}
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, p.Cwd); err == nil && filepath.IsLocal(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesApp accepts a process whose app label has one of apps as its
+// runtime ("java"), starts with it ("node:server") or, after the runtime,
+// contains it ("uvicorn"), ignoring case.
+func matchesApp(apps []string, p *ProcessIdentity) bool {
+	label := strings.ToLower(p.AppLabel)
+	runtime, app, ok := strings.Cut(label, ":")
+	if !ok {
+		return false
+	}
+	for _, a := range apps {
+		a = strings.ToLower(a)
+		if a == runtime || strings.HasPrefix(label, a) || (!strings.Contains(a, ":") && strings.Contains(app, a)) {
+			return true
+		}
+	}
+	return false
+}
+
 func containsProtocol(s []Protocol, v Protocol) bool {
 	for _, p := range s {
 		if p == v {
@@ -57,6 +187,15 @@ func containsProtocol(s []Protocol, v Protocol) bool {
 	return false
 }
 
+func containsFamily(s []AddressFamily, v AddressFamily) bool {
+	for _, f := range s {
+		if f == v {
+			return true
+		}
+	}
+	return false
+}
+
 func containsPort(s []uint16, v uint16) bool {
 	for _, p := range s {
 		if p == v {
@@ -75,6 +214,15 @@ func containsPID(s []int32, v int32) bool {
 	return false
 }
 
+func containsAnyPID(s []int32, owners []int32) bool {
+	for _, p := range owners {
+		if containsPID(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
 func containsState(s []SocketState, v SocketState) bool {
 	for _, st := range s {
 		if st == v {
@@ -83,3 +231,12 @@ func containsState(s []SocketState, v SocketState) bool {
 	}
 	return false
 }
+
+func containsPath(s []string, v string) bool {
+	for _, p := range s {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
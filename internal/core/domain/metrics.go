@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// ConnMetrics holds kernel-reported statistics for a single socket.
+// Queue depths are available for any inet socket; the remaining fields
+// are only populated for TCP.
+type ConnMetrics struct {
+	RecvQueue uint32
+	SendQueue uint32
+
+	RTT           time.Duration
+	RTTVar        time.Duration
+	Retransmits   uint32 // total retransmitted segments
+	Cwnd          uint32 // congestion window, in segments
+	BytesSent     uint64
+	BytesAcked    uint64
+	BytesReceived uint64
+	LastDataRecv  time.Duration // time since data was last received
+	HasTCPInfo    bool
+}
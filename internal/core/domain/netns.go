@@ -0,0 +1,25 @@
+package domain
+
+import "fmt"
+
+// NetNamespace identifies the Linux network namespace a socket lives in.
+// Sockets from the namespace porthog runs in carry no namespace unless
+// several namespaces were enumerated.
+type NetNamespace struct {
+	Inode   uint64 // nsfs inode, as shown by readlink /proc/<pid>/ns/net
+	Name    string // name under /run/netns, if any
+	PID     int32  // representative process inside the namespace (lowest PID), 0 if none
+	Process string // comm of PID
+}
+
+// String renders the namespace as its name, or its inode plus the
+// representative process, e.g. "4026532201 (nginx)".
+func (n *NetNamespace) String() string {
+	if n.Name != "" {
+		return n.Name
+	}
+	if n.Process != "" {
+		return fmt.Sprintf("%d (%s)", n.Inode, n.Process)
+	}
+	return fmt.Sprintf("%d", n.Inode)
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"fmt"
 	"net"
+	"strings"
 )
 
 // Protocol represents a network protocol type.
@@ -11,6 +12,10 @@ type Protocol uint8
 const (
 	TCP Protocol = iota
 	UDP
+	Unix
+	SCTP
+	Raw  // raw IP sockets (SOCK_RAW)
+	ICMP // unprivileged ICMP "ping" sockets
 )
 
 func (p Protocol) String() string {
@@ -19,6 +24,104 @@ func (p Protocol) String() string {
 		return "tcp"
 	case UDP:
 		return "udp"
+	case Unix:
+		return "unix"
+	case SCTP:
+		return "sctp"
+	case Raw:
+		return "raw"
+	case ICMP:
+		return "icmp"
+	default:
+		return "unknown"
+	}
+}
+
+// HasPorts reports whether sockets of this protocol are addressed by port.
+// Raw sockets have none, and ICMP sockets carry an echo identifier instead.
+func (p Protocol) HasPorts() bool {
+	return p == TCP || p == UDP || p == SCTP
+}
+
+// ipProtocolNames names common IP protocol numbers as /etc/protocols does.
+var ipProtocolNames = map[uint8]string{
+	1: "icmp", 2: "igmp", 4: "ipencap", 6: "tcp", 17: "udp", 41: "ipv6",
+	47: "gre", 50: "esp", 51: "ah", 58: "ipv6-icmp", 89: "ospf", 103: "pim",
+	112: "vrrp", 132: "sctp", 255: "raw",
+}
+
+// IPProtocolName returns the /etc/protocols name of an IP protocol number,
+// or the number itself.
+func IPProtocolName(n uint8) string {
+	if name, ok := ipProtocolNames[n]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// AddressFamily represents the address family of a socket.
+type AddressFamily uint8
+
+const (
+	FamilyUnknown AddressFamily = iota
+	FamilyIPv4
+	FamilyIPv6
+	FamilyUnix
+)
+
+func (f AddressFamily) String() string {
+	switch f {
+	case FamilyIPv4:
+		return "ipv4"
+	case FamilyIPv6:
+		return "ipv6"
+	case FamilyUnix:
+		return "unix"
+	default:
+		return "unknown"
+	}
+}
+
+// FamilyOf returns the address family of ip. IPv4-mapped IPv6 addresses
+// (::ffff:a.b.c.d) are reported as IPv4.
+func FamilyOf(ip net.IP) AddressFamily {
+	switch {
+	case ip.To4() != nil:
+		return FamilyIPv4
+	case len(ip) == net.IPv6len:
+		return FamilyIPv6
+	default:
+		return FamilyUnknown
+	}
+}
+
+// NormalizeIP collapses IPv4-mapped IPv6 addresses to their 4-byte form so
+// dual-stack sockets print and compare like plain IPv4 ones.
+func NormalizeIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip
+}
+
+// SocketType represents the communication semantics of a socket.
+type SocketType uint8
+
+const (
+	SockTypeUnknown SocketType = iota
+	SockStream
+	SockDgram
+	SockSeqpacket
+)
+
+func (t SocketType) String() string {
+	switch t {
+	case SockStream:
+		return "stream"
+	case SockDgram:
+		return "dgram"
+	case SockSeqpacket:
+		return "seqpacket"
 	default:
 		return "unknown"
 	}
@@ -34,35 +137,123 @@ const (
 	StateTimeWait
 	StateCloseWait
 	StateClosed
+	StateSynSent
+	StateSynRecv
+	StateFinWait1
+	StateFinWait2
+	StateLastAck
+	StateClosing
+	StateNewSynRecv
+	StateBoundInactive
 )
 
+var stateNames = map[SocketState]string{
+	StateListen:        "LISTEN",
+	StateEstablished:   "ESTABLISHED",
+	StateTimeWait:      "TIME_WAIT",
+	StateCloseWait:     "CLOSE_WAIT",
+	StateClosed:        "CLOSED",
+	StateSynSent:       "SYN_SENT",
+	StateSynRecv:       "SYN_RECV",
+	StateFinWait1:      "FIN_WAIT1",
+	StateFinWait2:      "FIN_WAIT2",
+	StateLastAck:       "LAST_ACK",
+	StateClosing:       "CLOSING",
+	StateNewSynRecv:    "NEW_SYN_RECV",
+	StateBoundInactive: "BOUND_INACTIVE",
+}
+
 func (s SocketState) String() string {
-	switch s {
-	case StateListen:
-		return "LISTEN"
-	case StateEstablished:
-		return "ESTABLISHED"
-	case StateTimeWait:
-		return "TIME_WAIT"
-	case StateCloseWait:
-		return "CLOSE_WAIT"
-	case StateClosed:
-		return "CLOSED"
-	default:
-		return "UNKNOWN"
+	if name, ok := stateNames[s]; ok {
+		return name
 	}
+	return "UNKNOWN"
+}
+
+// ParseSocketState resolves a state name such as "LISTEN", "fin-wait-1" or
+// "time_wait". Matching ignores case, dashes and underscores; "CLOSE" is
+// accepted as an alias for CLOSED.
+func ParseSocketState(name string) (SocketState, error) {
+	want := canonicalStateName(name)
+	if want == "CLOSE" {
+		return StateClosed, nil
+	}
+	for st, n := range stateNames {
+		if canonicalStateName(n) == want {
+			return st, nil
+		}
+	}
+	return StateUnknown, fmt.Errorf("%w: %q", ErrInvalidState, name)
+}
+
+func canonicalStateName(s string) string {
+	s = strings.ToUpper(s)
+	s = strings.ReplaceAll(s, "-", "")
+	return strings.ReplaceAll(s, "_", "")
+}
+
+// OwnerKind classifies who holds a socket, so sockets without a PID can be
+// told apart.
+type OwnerKind int
+
+const (
+	OwnerUnknown OwnerKind = iota // not classified by the platform
+	OwnerProcess                  // held by the process(es) in Owners
+	OwnerHidden                   // held by a process this user cannot inspect (hidepid, other users)
+	OwnerKernel                   // held by the kernel itself (nfsd, WireGuard, ...)
+	OwnerNone                     // no longer owned by anything (TIME_WAIT, inode 0)
+)
+
+var ownerKindNames = map[OwnerKind]string{
+	OwnerUnknown: "unknown",
+	OwnerProcess: "process",
+	OwnerHidden:  "hidden",
+	OwnerKernel:  "kernel",
+	OwnerNone:    "none",
+}
+
+func (k OwnerKind) String() string {
+	if s, ok := ownerKindNames[k]; ok {
+		return s
+	}
+	return "unknown"
+}
+
+// SocketOwner is a process holding one or more file descriptors for a socket.
+type SocketOwner struct {
+	PID     int32
+	FDs     []int32
+	Process *ProcessIdentity
 }
 
 // PortBinding represents a network socket bound to a port with its owner process.
 type PortBinding struct {
-	Protocol  Protocol
-	LocalIP   net.IP
-	LocalPort uint16
-	RemoteIP  net.IP
+	Protocol   Protocol
+	Family     AddressFamily
+	LocalIP    net.IP
+	LocalPort  uint16
+	LocalIPs   []net.IP // SCTP only: every address of a multi-homed endpoint, LocalIP first
+	RemoteIP   net.IP
 	RemotePort uint16
-	State     SocketState
-	PID       int32
-	Process   *ProcessIdentity
+	Path       string     // Unix domain sockets only; abstract names start with "@"
+	SockType   SocketType // Unix domain sockets only
+	IPProtocol uint8      // raw and ICMP sockets only: IP protocol number (1 icmp, 58 ipv6-icmp, 255 raw)
+	IfIndex    uint32     // device the socket is bound to (SO_BINDTODEVICE) or its IPv6 scope; 0 when unbound
+	Interface  string     // name of IfIndex, else of the interface owning LocalIP
+	State      SocketState
+	PID        int32            // primary owner (lowest PID when shared)
+	Process    *ProcessIdentity // identity of PID
+	Owners     []SocketOwner    // every process holding the socket, sorted by PID
+	Metrics    *ConnMetrics     // only set when metrics were requested
+	Netns      *NetNamespace    // only set when several namespaces were enumerated
+	SocketUnit string           // systemd .socket unit the socket is held for (socket activation)
+
+	// Socket-level ownership, available even when no PID is. UID is the
+	// socket's owner as recorded by the kernel; Username is resolved from it.
+	OwnerKind OwnerKind
+	UID       uint32
+	HasUID    bool
+	Username  string
 }
 
 // IsListening returns true if the socket is in LISTEN state.
@@ -70,9 +261,48 @@ func (pb *PortBinding) IsListening() bool {
 	return pb.State == StateListen
 }
 
-// LocalAddr returns the local address as "ip:port".
+// OwnerPIDs returns every PID holding the socket. Adapters that only report
+// a single owner yield just PID.
+func (pb *PortBinding) OwnerPIDs() []int32 {
+	if len(pb.Owners) == 0 {
+		if pb.PID == 0 {
+			return nil
+		}
+		return []int32{pb.PID}
+	}
+	pids := make([]int32, len(pb.Owners))
+	for i, o := range pb.Owners {
+		pids[i] = o.PID
+	}
+	return pids
+}
+
+// LocalAddr returns the local address as "ip:port", the socket path for
+// Unix domain sockets, or "ip:protocol" for raw sockets.
 func (pb *PortBinding) LocalAddr() string {
-	return net.JoinHostPort(pb.LocalIP.String(), fmt.Sprintf("%d", pb.LocalPort))
+	if pb.Protocol == Unix {
+		return pb.Path
+	}
+	if pb.Protocol == Raw {
+		// Raw sockets are bound to a protocol rather than a port, e.g. "0.0.0.0:icmp".
+		return net.JoinHostPort(pb.localHost(), IPProtocolName(pb.IPProtocol))
+	}
+	return net.JoinHostPort(pb.localHost(), fmt.Sprintf("%d", pb.LocalPort))
+}
+
+// localHost returns LocalIP, zoned with the interface for IPv6 link-local
+// addresses (fe80::1%eth0), which are meaningless without one.
+func (pb *PortBinding) localHost() string {
+	if pb.Interface != "" && pb.Family == FamilyIPv6 && pb.LocalIP.IsLinkLocalUnicast() {
+		return pb.LocalIP.String() + "%" + pb.Interface
+	}
+	return pb.LocalIP.String()
+}
+
+// IsWildcard reports whether an IP socket listens on every interface: it is
+// bound to the unspecified address and not pinned to a device.
+func (pb *PortBinding) IsWildcard() bool {
+	return pb.IfIndex == 0 && pb.LocalIP != nil && pb.LocalIP.IsUnspecified()
 }
 
 // RemoteAddr returns the remote address as "ip:port".
@@ -82,3 +312,25 @@ func (pb *PortBinding) RemoteAddr() string {
 	}
 	return net.JoinHostPort(pb.RemoteIP.String(), fmt.Sprintf("%d", pb.RemotePort))
 }
+
+// OwnerName returns the owning process's name, or a placeholder such as
+// "[kernel]" or "[hidden]" explaining why there is none.
+func (pb *PortBinding) OwnerName() string {
+	if pb.Process != nil && pb.Process.Name != "" {
+		return pb.Process.Name
+	}
+	switch pb.OwnerKind {
+	case OwnerHidden, OwnerKernel:
+		return "[" + pb.OwnerKind.String() + "]"
+	}
+	return "-"
+}
+
+// OwnerUser returns the owning process's user, falling back to the
+// socket's own UID when the process is unknown.
+func (pb *PortBinding) OwnerUser() string {
+	if pb.Process != nil && pb.Process.Username != "" {
+		return pb.Process.Username
+	}
+	return pb.Username
+}
@@ -1,14 +1,72 @@
 package domain
 
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
 // ProcessIdentity holds metadata about a process that owns a port binding.
 type ProcessIdentity struct {
-	PID             int32
-	CreateTimeMs    int64
-	Name            string
-	Exe             string
-	Cmdline         string
-	Username        string
+	PID              int32
+	PPID             int32
+	CreateTimeMs     int64
+	Name             string
+	Exe              string
+	Cmdline          string
+	Username         string
 	PermissionDenied bool
+
+	// AppLabel names the application a runtime is running, e.g.
+	// "java:com.acme.ApiServer", "node:server.js" or "go:./bin/api". Empty
+	// when the process was not recognised.
+	AppLabel string
+
+	// Container attribution, derived from the process's cgroup. Both are
+	// empty for processes running directly on the host.
+	ContainerRuntime string // docker, containerd, cri-o, cri, podman or nspawn
+	ContainerID      string // full container ID, or machine name for nspawn
+
+	// SystemdUnit is the service or scope unit the process runs in, derived
+	// from its cgroup, e.g. "nginx.service". Empty outside systemd and for
+	// containerized processes.
+	SystemdUnit string
+
+	// Cwd is the process's working directory and ProjectRoot the nearest
+	// directory at or above it that looks like a project checkout (holding
+	// .git, go.mod, package.json or pyproject.toml). Both are paths as the
+	// process sees them, empty when unreadable or not found.
+	Cwd         string
+	ProjectRoot string
+
+	// Usage is the process's resource usage when it was resolved. Only
+	// filled in when requested.
+	Usage *ProcessUsage
+
+	// Ancestors are the process's parent, grandparent and so on, nearest
+	// first, up to but excluding PID 1. Only filled in when requested.
+	Ancestors []ProcessRef
+}
+
+// ProcessUsage is a snapshot of a process's resource consumption.
+type ProcessUsage struct {
+	// CPUPercent is the CPU time used over the sampling window as a
+	// percentage of one CPU, so it exceeds 100 for multi-threaded load. It
+	// is negative until a second sample has been taken.
+	CPUPercent float64
+	RSS        uint64 // resident memory in bytes
+	FDs        int32  // open file descriptors (handles on Windows), -1 if unknown
+}
+
+// HasCPU reports whether CPUPercent has been measured.
+func (u *ProcessUsage) HasCPU() bool {
+	return u.CPUPercent >= 0
+}
+
+// ProcessRef names another process, such as an ancestor.
+type ProcessRef struct {
+	PID  int32
+	Name string
 }
 
 // IsEnriched returns true if process metadata was successfully resolved.
@@ -16,6 +74,57 @@ func (p *ProcessIdentity) IsEnriched() bool {
 	return p.Name != "" || p.Exe != ""
 }
 
+// Lineage renders the process and its ancestors, e.g. "node ← npm ← zsh".
+func (p *ProcessIdentity) Lineage() string {
+	parts := make([]string, 0, len(p.Ancestors)+1)
+	parts = append(parts, p.Name)
+	for _, a := range p.Ancestors {
+		parts = append(parts, a.Name)
+	}
+	return strings.Join(parts, " ← ")
+}
+
+// ProjectName returns the last element of ProjectRoot, e.g. "api" for
+// /home/dev/src/api, or "" when no project was detected.
+func (p *ProcessIdentity) ProjectName() string {
+	if p.ProjectRoot == "" {
+		return ""
+	}
+	return filepath.Base(p.ProjectRoot)
+}
+
+// StartTime returns when the process started, or the zero time when its
+// create time is unknown.
+func (p *ProcessIdentity) StartTime() time.Time {
+	if p.CreateTimeMs == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(p.CreateTimeMs)
+}
+
+// Uptime returns how long the process has been running at now, or 0 when
+// its create time is unknown.
+func (p *ProcessIdentity) Uptime(now time.Time) time.Duration {
+	if p.CreateTimeMs == 0 {
+		return 0
+	}
+	return now.Sub(p.StartTime())
+}
+
+// InContainer reports whether the process was attributed to a container.
+func (p *ProcessIdentity) InContainer() bool {
+	return p.ContainerID != ""
+}
+
+// ShortContainerID returns the container ID abbreviated the way container
+// CLIs print it (12 characters).
+func (p *ProcessIdentity) ShortContainerID() string {
+	if len(p.ContainerID) > 12 && p.ContainerRuntime != "nspawn" {
+		return p.ContainerID[:12]
+	}
+	return p.ContainerID
+}
+
 // MatchesIdentity checks if another process identity refers to the same process
 // by comparing PID and create time (TOCTOU protection).
 func (p *ProcessIdentity) MatchesIdentity(other *ProcessIdentity) bool {
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"net"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 )
@@ -10,3 +11,13 @@ import (
 type PortAllocator interface {
 	FindFree(ctx context.Context, protocol domain.Protocol, portRange *domain.PortRange, count int) ([]uint16, error)
 }
+
+// SCTPProber bind-checks SCTP ports, which the standard library cannot open.
+type SCTPProber interface {
+	// Available fails with domain.ErrUnsupported when SCTP sockets cannot
+	// be created.
+	Available() error
+	// PortFree reports whether port can be bound on addr, or on the
+	// wildcard address when addr is nil.
+	PortFree(addr *net.IPAddr, port uint16) bool
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 )
@@ -10,3 +11,57 @@ import (
 type Enumerator interface {
 	List(ctx context.Context, filter *domain.Filter) (*domain.PartialResult[[]domain.PortBinding], error)
 }
+
+// EnumeratorOptions selects optional, more expensive enumeration work.
+// Platforms that cannot honour an option ignore it.
+type EnumeratorOptions struct {
+	// Metrics collects per-socket queue depths and TCP statistics.
+	Metrics bool
+
+	// AllNamespaces enumerates every network namespace in use on the host,
+	// not just the caller's, and labels each binding with its namespace.
+	AllNamespaces bool
+
+	// Netns restricts enumeration to one network namespace, given as the
+	// PID of a process inside it or a name under /run/netns.
+	Netns string
+
+	// Incremental keeps socket ownership between List calls and rescans
+	// only processes whose descriptors changed, for repeated listings such
+	// as watch mode.
+	Incremental bool
+
+	// ProcRoot is where procfs is mounted, "/proc" when empty. Pointing it
+	// at another system's procfs, such as a host's /proc mounted into a
+	// container, lists that system's sockets and processes.
+	ProcRoot string
+
+	// Backend selects where Linux reads its socket tables from.
+	Backend Backend
+}
+
+// Backend selects the socket source of the Linux enumerator.
+type Backend string
+
+const (
+	// BackendAuto uses sock_diag netlink, falling back to procfs where it is
+	// unavailable. With a ProcRoot other than /proc, whose sockets netlink
+	// cannot see, it reads procfs only.
+	BackendAuto Backend = "auto"
+	// BackendNetlink uses sock_diag only and reports failed dumps as
+	// warnings instead of falling back.
+	BackendNetlink Backend = "netlink"
+	// BackendProc parses the tables under <ProcRoot>/net.
+	BackendProc Backend = "proc"
+)
+
+// ParseBackend validates a backend name; "" selects BackendAuto.
+func ParseBackend(s string) (Backend, error) {
+	switch b := Backend(s); b {
+	case "":
+		return BackendAuto, nil
+	case BackendAuto, BackendNetlink, BackendProc:
+		return b, nil
+	}
+	return "", fmt.Errorf("invalid socket backend %q (must be auto|netlink|proc)", s)
+}
@@ -0,0 +1,26 @@
+package ports
+
+import "context"
+
+// ProcessEventType classifies a ProcessEvent.
+type ProcessEventType int
+
+const (
+	ProcessExec ProcessEventType = iota
+	ProcessExit
+)
+
+// ProcessEvent is a process replacing its program image or exiting.
+type ProcessEvent struct {
+	Type ProcessEventType
+	PID  int32
+}
+
+// ProcessMonitor reports process lifecycle events, which let cached process
+// metadata be dropped as soon as it goes stale.
+type ProcessMonitor interface {
+	// Events reports processes as they exec and exit until ctx is done,
+	// then closes the channel. It fails with domain.ErrUnsupported or
+	// domain.ErrPermissionDenied when the platform cannot report them.
+	Events(ctx context.Context) (<-chan ProcessEvent, error)
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 )
@@ -12,3 +13,30 @@ type ProcessResolver interface {
 	// InvalidatePID removes a PID from the cache, forcing fresh lookup on next Enrich.
 	InvalidatePID(pid int32)
 }
+
+// ProcessTree navigates the parent/child relationships between processes.
+// Resolvers that can walk the process table implement it.
+type ProcessTree interface {
+	// Ancestors returns pid's parent, grandparent and so on, nearest first,
+	// up to but excluding PID 1.
+	Ancestors(ctx context.Context, pid int32) ([]domain.ProcessIdentity, error)
+	// Descendants returns every process below pid, parents before their
+	// children.
+	Descendants(ctx context.Context, pid int32) ([]domain.ProcessIdentity, error)
+}
+
+// UsageSampler measures the resource usage of running processes. Resolvers
+// that can read it implement it.
+type UsageSampler interface {
+	// SampleUsage returns the usage of each of pids that could be read. CPU
+	// usage is measured since the previous sample of the same process and
+	// is unknown for processes sampled for the first time.
+	SampleUsage(ctx context.Context, pids []int32) map[int32]*domain.ProcessUsage
+}
+
+// IdentityCache is implemented by resolvers that cache identities, so
+// callers keeping identities of their own hold them no longer.
+type IdentityCache interface {
+	// CacheTTL is how long a resolved identity is reused.
+	CacheTTL() time.Duration
+}
@@ -11,14 +11,25 @@ type SignalPolicy struct {
 	Force       bool
 	ForceSystem bool
 	DryRun      bool
+	// AllOwners terminates every process holding a shared socket instead of
+	// only their common parent.
+	AllOwners bool
+	// Parent targets the nearest ancestor of the socket's holder that is not
+	// a shell, such as the npm or nodemon that would respawn it.
+	Parent bool
+	// Tree terminates every process below the target too.
+	Tree bool
 }
 
 // TerminateResult holds the outcome of a termination attempt.
 type TerminateResult struct {
 	PID       int32
+	PIDs      []int32 // every PID targeted; more than one for --all-owners or --tree
 	Port      uint16
+	Path      string
 	Protocol  domain.Protocol
 	Process   *domain.ProcessIdentity
+	Holder    *domain.ProcessIdentity // the socket's holder, when Parent targeted its ancestor
 	Killed    bool
 	DryRun    bool
 	Blocked   bool
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 )
@@ -12,7 +13,29 @@ type Snapshot struct {
 	Partial  bool
 }
 
-// Watcher provides periodic snapshots of port bindings for watch mode.
+// Watcher provides snapshots of port bindings for watch mode and, where the
+// platform can notify, a stream of changes between them.
 type Watcher interface {
 	Snapshot(ctx context.Context, filter *domain.Filter) (*Snapshot, error)
+
+	// Events reports sockets as they appear and disappear until ctx is
+	// done, then closes the channel. It fails with domain.ErrUnsupported or
+	// domain.ErrPermissionDenied when changes cannot be observed, in which
+	// case callers keep polling Snapshot.
+	Events(ctx context.Context, filter *domain.Filter) (<-chan WatchEvent, error)
+}
+
+// WatchEventType classifies a WatchEvent.
+type WatchEventType int
+
+const (
+	WatchAdded WatchEventType = iota
+	WatchRemoved
+)
+
+// WatchEvent is one socket appearing or disappearing.
+type WatchEvent struct {
+	Type    WatchEventType
+	Binding domain.PortBinding
+	At      time.Time
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
 const (
@@ -14,19 +16,50 @@ const (
 )
 
 // FindFreePortService discovers available ports by attempting to bind.
-type FindFreePortService struct{}
+type FindFreePortService struct {
+	sctp ports.SCTPProber
+}
 
 // NewFindFreePortService creates a new FindFreePortService.
 func NewFindFreePortService() *FindFreePortService {
 	return &FindFreePortService{}
 }
 
+// SetSCTPProber makes SCTP ports bind-checked with p. Without one, SCTP is
+// unsupported.
+func (s *FindFreePortService) SetSCTPProber(p ports.SCTPProber) {
+	s.sctp = p
+}
+
 // FindFree finds available ports in the given range by bind-checking.
 func (s *FindFreePortService) FindFree(ctx context.Context, proto domain.Protocol, portRange *domain.PortRange, count int) ([]uint16, error) {
+	return s.FindFreeOnInterface(ctx, proto, "", portRange, count)
+}
+
+// FindFreeOnInterface is FindFree for ports that can be bound on every
+// address of the named interface. An empty name checks the wildcard address.
+func (s *FindFreePortService) FindFreeOnInterface(ctx context.Context, proto domain.Protocol, iface string, portRange *domain.PortRange, count int) ([]uint16, error) {
 	if count <= 0 {
 		count = 1
 	}
 
+	var addrs []net.IPAddr
+	if iface != "" {
+		var err error
+		if addrs, err = interfaceAddrs(iface); err != nil {
+			return nil, err
+		}
+	}
+
+	if proto == domain.SCTP {
+		if s.sctp == nil {
+			return nil, fmt.Errorf("%w: no SCTP prober configured", domain.ErrUnsupported)
+		}
+		if err := s.sctp.Available(); err != nil {
+			return nil, err
+		}
+	}
+
 	r := domain.PortRange{Start: defaultRangeStart, End: defaultRangeEnd}
 	if portRange != nil {
 		if !portRange.Valid() {
@@ -42,7 +75,7 @@ func (s *FindFreePortService) FindFree(ctx context.Context, proto domain.Protoco
 			return found, ctx.Err()
 		default:
 		}
-		if isPortFree(proto, port) {
+		if s.isPortFree(proto, addrs, port) {
 			found = append(found, port)
 		}
 	}
@@ -53,13 +86,67 @@ func (s *FindFreePortService) FindFree(ctx context.Context, proto domain.Protoco
 	return found, nil
 }
 
-func isPortFree(proto domain.Protocol, port uint16) bool {
-	network := "tcp"
+// interfaceAddrs returns the addresses of the named interface, zoned when
+// they are IPv6 link-local.
+func interfaceAddrs(name string) ([]net.IPAddr, error) {
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrUnknownInterface, name)
+	}
+	ifAddrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	var addrs []net.IPAddr
+	for _, a := range ifAddrs {
+		ipn, ok := a.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		addr := net.IPAddr{IP: ipn.IP}
+		if ipn.IP.To4() == nil && ipn.IP.IsLinkLocalUnicast() {
+			addr.Zone = ifi.Name
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("interface %s has no addresses", name)
+	}
+	return addrs, nil
+}
+
+// isPortFree reports whether port can be bound on each of addrs, or on the
+// wildcard address when addrs is empty.
+func (s *FindFreePortService) isPortFree(proto domain.Protocol, addrs []net.IPAddr, port uint16) bool {
+	if len(addrs) == 0 {
+		return s.isAddrPortFree(proto, nil, port)
+	}
+	for i := range addrs {
+		if !s.isAddrPortFree(proto, &addrs[i], port) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *FindFreePortService) isAddrPortFree(proto domain.Protocol, addr *net.IPAddr, port uint16) bool {
+	if proto == domain.SCTP {
+		return s.sctp.PortFree(addr, port)
+	}
+	host := ""
+	if addr != nil {
+		host = addr.String()
+	}
+	hostPort := net.JoinHostPort(host, strconv.Itoa(int(port)))
 	if proto == domain.UDP {
-		network = "udp"
+		conn, err := net.ListenPacket("udp", hostPort)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
 	}
-	addr := fmt.Sprintf(":%d", port)
-	ln, err := net.Listen(network, addr)
+	ln, err := net.Listen("tcp", hostPort)
 	if err != nil {
 		return false
 	}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
@@ -13,11 +15,11 @@ import (
 var criticalPIDs = map[int32]bool{0: true, 1: true}
 
 var criticalNames = map[string]bool{
-	"systemd":   true,
-	"launchd":   true,
-	"init":      true,
-	"csrss.exe": true,
-	"smss.exe":  true,
+	"systemd":     true,
+	"launchd":     true,
+	"init":        true,
+	"csrss.exe":   true,
+	"smss.exe":    true,
 	"wininit.exe": true,
 }
 
@@ -42,41 +44,74 @@ func NewKillByPortService(e ports.Enumerator, r ports.ProcessResolver, t ports.T
 
 // Kill terminates the process on the specified port with two-phase TOCTOU validation.
 func (s *KillByPortService) Kill(ctx context.Context, port uint16, proto domain.Protocol, policy ports.SignalPolicy) (*ports.TerminateResult, error) {
-	// Phase 1: Enumerate and identify target
-	filter := &domain.Filter{
+	return s.KillMatching(ctx, &domain.Filter{
 		Ports:     []uint16{port},
 		Protocols: []domain.Protocol{proto},
-		States:    []domain.SocketState{domain.StateListen},
+	}, policy)
+}
+
+// KillMatching terminates the process owning the first socket that satisfies
+// scope, with the same two-phase TOCTOU validation as Kill. Unless scope
+// names states explicitly, only listening sockets are considered.
+func (s *KillByPortService) KillMatching(ctx context.Context, scope *domain.Filter, policy ports.SignalPolicy) (*ports.TerminateResult, error) {
+	// Phase 1: Enumerate and identify target
+	filter := *scope
+	if len(filter.States) == 0 {
+		filter.States = []domain.SocketState{domain.StateListen}
 	}
-	result, err := s.enumerator.List(ctx, filter)
+	result, err := s.enumerator.List(ctx, &filter)
 	if err != nil {
 		return nil, err
 	}
 	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("%w: no process found on %s port %d", domain.ErrNotFound, proto, port)
+		return nil, fmt.Errorf("%w: no process found matching %s", domain.ErrNotFound, describeScope(&filter))
 	}
 
-	target := result.Data[0]
-	enriched, enrichErr := s.resolver.Enrich(ctx, []domain.PortBinding{target})
-	if enrichErr != nil {
-		return nil, fmt.Errorf("cannot safely identify target process: %w", enrichErr)
-	}
-	if len(enriched) > 0 {
-		target = enriched[0]
+	target, err := s.chooseTarget(ctx, &filter, result.Data)
+	if err != nil {
+		return nil, err
 	}
 
 	res := &ports.TerminateResult{
 		PID:      target.PID,
-		Port:     port,
-		Protocol: proto,
+		Port:     target.LocalPort,
+		Path:     target.Path,
+		Protocol: target.Protocol,
 		Process:  target.Process,
 	}
 
+	if target.PID == 0 {
+		if err := unownedError(&target); err != nil {
+			return res, err
+		}
+	}
+
+	victims, err := selectVictims(&target, policy)
+	if err != nil {
+		return res, err
+	}
+	targets, err := s.expandVictims(ctx, victims, policy)
+	if err != nil {
+		return res, err
+	}
+	res.PID, res.Process = targets[0].PID, targets[0].Process
+	if policy.Parent {
+		res.Holder = victims[0].Process
+	}
+	for _, v := range targets {
+		res.PIDs = append(res.PIDs, v.PID)
+	}
+
 	// Check critical process protection
-	if isCritical(target.PID, target.Process) && !policy.ForceSystem {
-		res.Blocked = true
-		res.BlockedBy = "critical system process"
-		return res, domain.ErrCriticalProcess
+	for _, v := range targets {
+		if isCritical(v.PID, v.Process) && !policy.ForceSystem {
+			res.Blocked = true
+			if isSystemd(v) {
+				return res, socketActivatedError(&target, res)
+			}
+			res.BlockedBy = "critical system process"
+			return res, domain.ErrCriticalProcess
+		}
 	}
 
 	if policy.DryRun {
@@ -86,39 +121,442 @@ func (s *KillByPortService) Kill(ctx context.Context, port uint16, proto domain.
 
 	// Phase 2: Revalidate before kill (TOCTOU protection)
 	// Invalidate cache to force fresh process identity lookup
-	s.resolver.InvalidatePID(target.PID)
+	for _, v := range slices.Concat(victims, targets) {
+		s.resolver.InvalidatePID(v.PID)
+	}
 
-	recheck, err := s.enumerator.List(ctx, filter)
+	recheck, err := s.enumerator.List(ctx, &filter)
 	if err != nil {
 		return nil, err
 	}
-	if len(recheck.Data) == 0 {
+	current := findSocket(recheck.Data, &target)
+	if current == nil {
 		return nil, domain.ErrProcessExited
 	}
-	if recheck.Data[0].PID != target.PID {
-		return nil, fmt.Errorf("%w: PID changed from %d to %d", domain.ErrOwnershipConflict, target.PID, recheck.Data[0].PID)
+	holders := current.OwnerPIDs()
+	for _, v := range victims {
+		if !containsInt32(holders, v.PID) {
+			return nil, fmt.Errorf("%w: PID %d no longer holds the socket (now held by %v)", domain.ErrOwnershipConflict, v.PID, holders)
+		}
 	}
 
 	// Validate create_time if available (guards against PID reuse)
-	if target.Process != nil && target.Process.CreateTimeMs > 0 {
-		recheckEnriched, err := s.resolver.Enrich(ctx, []domain.PortBinding{recheck.Data[0]})
-		if err != nil || len(recheckEnriched) == 0 || recheckEnriched[0].Process == nil {
+	if needsIdentityCheck(victims) {
+		recheckEnriched, err := s.resolver.Enrich(ctx, []domain.PortBinding{*current})
+		if err != nil || len(recheckEnriched) == 0 {
 			return nil, fmt.Errorf("cannot revalidate process identity before termination: %w", domain.ErrOwnershipConflict)
 		}
-		if !target.Process.MatchesIdentity(recheckEnriched[0].Process) {
-			return nil, fmt.Errorf("%w: process identity changed (PID reuse detected)", domain.ErrOwnershipConflict)
+		identities := ownerIdentities(&recheckEnriched[0])
+		for _, v := range victims {
+			if v.Process == nil || v.Process.CreateTimeMs == 0 {
+				continue
+			}
+			if identities[v.PID] == nil {
+				return nil, fmt.Errorf("cannot revalidate process identity before termination: %w", domain.ErrOwnershipConflict)
+			}
+			if !v.Process.MatchesIdentity(identities[v.PID]) {
+				return nil, fmt.Errorf("%w: process identity changed (PID reuse detected)", domain.ErrOwnershipConflict)
+			}
 		}
 	}
 
-	// Execute termination
-	if err := s.terminator.Terminate(ctx, target.PID, policy); err != nil {
+	// Processes added by --parent or --tree do not hold the socket, so
+	// they are revalidated on their own.
+	if err := s.revalidate(ctx, targets, victims); err != nil {
 		return nil, err
 	}
 
+	// Execute termination. Processes below the first target often exit
+	// along with it before their turn comes.
+	for i, v := range targets {
+		if err := s.terminator.Terminate(ctx, v.PID, policy); err != nil {
+			if i > 0 && policy.Tree && errors.Is(err, domain.ErrProcessExited) {
+				continue
+			}
+			res.PIDs = res.PIDs[:i]
+			return res, fmt.Errorf("terminating PID %d: %w", v.PID, err)
+		}
+	}
+
 	res.Killed = true
 	return res, nil
 }
 
+// KillEach terminates the holders of every socket that satisfies scope, one
+// socket at a time through KillMatching. Unless scope names states
+// explicitly, only listening sockets are considered. Sockets whose holders
+// were already handled, or that went away as earlier processes were
+// stopped, are skipped. It stops at the first failure, returning the results
+// so far.
+func (s *KillByPortService) KillEach(ctx context.Context, scope *domain.Filter, policy ports.SignalPolicy) ([]*ports.TerminateResult, error) {
+	filter := *scope
+	if len(filter.States) == 0 {
+		filter.States = []domain.SocketState{domain.StateListen}
+	}
+	result, err := s.enumerator.List(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+	bindings, err := s.resolver.Enrich(ctx, result.Data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot safely identify target processes: %w", err)
+	}
+
+	var results []*ports.TerminateResult
+	handled := make(map[int32]bool)
+	for i := range bindings {
+		b := &bindings[i]
+		if !filter.MatchesProcess(b) || b.PID == 0 || slices.ContainsFunc(b.OwnerPIDs(), func(pid int32) bool { return handled[pid] }) {
+			continue
+		}
+		res, err := s.KillMatching(ctx, socketScope(b, &filter), policy)
+		if err != nil {
+			if len(results) > 0 && (errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrProcessExited)) {
+				continue
+			}
+			if res != nil {
+				results = append(results, res)
+			}
+			return results, err
+		}
+		for _, pid := range slices.Concat(b.OwnerPIDs(), res.PIDs) {
+			handled[pid] = true
+		}
+		results = append(results, res)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%w: no process found matching %s", domain.ErrNotFound, describeScope(&filter))
+	}
+	return results, nil
+}
+
+// socketScope narrows filter down to the protocol, family and port or path
+// of b, keeping its process-level criteria.
+func socketScope(b *domain.PortBinding, filter *domain.Filter) *domain.Filter {
+	scope := *filter
+	scope.Protocols = []domain.Protocol{b.Protocol}
+	if b.Protocol == domain.Unix {
+		scope.Families, scope.Ports, scope.Paths = nil, nil, []string{b.Path}
+	} else {
+		scope.Families, scope.Ports, scope.Paths = []domain.AddressFamily{b.Family}, []uint16{b.LocalPort}, nil
+	}
+	return &scope
+}
+
+// chooseTarget returns the first candidate socket, enriched with its
+// holders' identities. When filter has process-level criteria every
+// candidate is enriched and the first whose holders satisfy them is chosen.
+func (s *KillByPortService) chooseTarget(ctx context.Context, filter *domain.Filter, candidates []domain.PortBinding) (domain.PortBinding, error) {
+	if !filter.HasProcessCriteria() {
+		candidates = candidates[:1]
+	}
+	target := candidates[0]
+	enriched, err := s.resolver.Enrich(ctx, candidates)
+	if err != nil {
+		return target, fmt.Errorf("cannot safely identify target process: %w", err)
+	}
+	if !filter.HasProcessCriteria() {
+		if len(enriched) > 0 {
+			target = enriched[0]
+		}
+		return target, nil
+	}
+	for i := range enriched {
+		if filter.MatchesProcess(&enriched[i]) {
+			return enriched[i], nil
+		}
+	}
+	return target, fmt.Errorf("%w: no process found matching %s", domain.ErrNotFound, describeScope(filter))
+}
+
+// findSocket returns the binding in bindings for the same socket as target:
+// the same protocol and local address or path.
+func findSocket(bindings []domain.PortBinding, target *domain.PortBinding) *domain.PortBinding {
+	for i := range bindings {
+		b := &bindings[i]
+		if b.Protocol == target.Protocol && b.LocalPort == target.LocalPort && b.Path == target.Path &&
+			b.LocalIP.Equal(target.LocalIP) {
+			return b
+		}
+	}
+	return nil
+}
+
+type victim struct {
+	PID     int32
+	Process *domain.ProcessIdentity
+}
+
+// selectVictims decides which holders of a socket to terminate. A socket held
+// by one process targets that process. A shared socket (pre-forked workers
+// inheriting the listener) targets the holder that is the parent of all the
+// others, such as an nginx or gunicorn master, unless policy.AllOwners asks
+// for every holder. Anything else is ambiguous and refused.
+func selectVictims(b *domain.PortBinding, policy ports.SignalPolicy) ([]victim, error) {
+	if len(b.Owners) <= 1 {
+		return []victim{{PID: b.PID, Process: b.Process}}, nil
+	}
+
+	if policy.AllOwners {
+		victims := make([]victim, len(b.Owners))
+		for i, o := range b.Owners {
+			victims[i] = victim{PID: o.PID, Process: o.Process}
+		}
+		return victims, nil
+	}
+
+	if parent := commonParent(b.Owners); parent != nil {
+		return []victim{{PID: parent.PID, Process: parent.Process}}, nil
+	}
+	return nil, fmt.Errorf("%w: held by PIDs %v with no common parent among them; use --all-owners to terminate all of them",
+		domain.ErrSharedSocket, b.OwnerPIDs())
+}
+
+// commonParent returns the owner whose children are all the other owners.
+func commonParent(owners []domain.SocketOwner) *domain.SocketOwner {
+	for i := range owners {
+		parent := &owners[i]
+		ok := true
+		for j := range owners {
+			if i == j {
+				continue
+			}
+			if owners[j].Process == nil || owners[j].Process.PPID != parent.PID {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return parent
+		}
+	}
+	return nil
+}
+
+// expandVictims applies policy.Parent and policy.Tree to the holders chosen
+// by selectVictims, returning the processes to terminate in order: the
+// targets themselves before the processes below them, which they might
+// otherwise respawn.
+func (s *KillByPortService) expandVictims(ctx context.Context, holders []victim, policy ports.SignalPolicy) ([]victim, error) {
+	if !policy.Parent && !policy.Tree {
+		return holders, nil
+	}
+	tree, ok := s.resolver.(ports.ProcessTree)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot walk the process tree", domain.ErrUnsupported)
+	}
+
+	roots := holders
+	if policy.Parent {
+		parent, err := nearestSupervisor(ctx, tree, holders[0])
+		if err != nil {
+			return nil, err
+		}
+		roots = []victim{parent}
+	}
+	if !policy.Tree {
+		return roots, nil
+	}
+
+	targets := slices.Clone(roots)
+	seen := make(map[int32]bool)
+	for _, v := range roots {
+		seen[v.PID] = true
+	}
+	for _, root := range roots {
+		below, err := tree.Descendants(ctx, root.PID)
+		if err != nil {
+			return nil, fmt.Errorf("listing the processes below PID %d: %w", root.PID, err)
+		}
+		for i := range below {
+			if !seen[below[i].PID] {
+				seen[below[i].PID] = true
+				targets = append(targets, victim{PID: below[i].PID, Process: &below[i]})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// shellNames are skipped when looking for the process that launched a
+// socket's holder: `npm run dev` starts its script through sh.
+var shellNames = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "fish": true, "dash": true, "ash": true,
+	"ksh": true, "mksh": true, "csh": true, "tcsh": true, "nu": true,
+	"pwsh": true, "pwsh.exe": true, "powershell.exe": true, "cmd.exe": true, "bash.exe": true,
+}
+
+// sessionNames are processes hosting an interactive session. A holder whose
+// nearest non-shell ancestor is one of them was started by hand, and
+// terminating the ancestor would end the user's session instead.
+var sessionNames = map[string]bool{
+	"sshd": true, "sshd-session": true, "login": true, "su": true, "sudo": true, "doas": true,
+	"tmux": true, "tmux: server": true, "screen": true, "SCREEN": true,
+	"gnome-terminal-server": true, "konsole": true, "xterm": true, "alacritty": true,
+	"kitty": true, "wezterm-gui": true, "Terminal": true, "iTerm2": true,
+	"explorer.exe": true, "conhost.exe": true, "WindowsTerminal.exe": true, "OpenConsole.exe": true,
+}
+
+// nearestSupervisor returns the nearest ancestor of v that is not a shell.
+func nearestSupervisor(ctx context.Context, tree ports.ProcessTree, v victim) (victim, error) {
+	ancestors, err := tree.Ancestors(ctx, v.PID)
+	if err != nil {
+		return victim{}, fmt.Errorf("finding the parent of PID %d: %w", v.PID, err)
+	}
+	name := "process"
+	if v.Process != nil && v.Process.Name != "" {
+		name = v.Process.Name
+	}
+	for i := range ancestors {
+		a := &ancestors[i]
+		base := strings.TrimPrefix(a.Name, "-") // login shells
+		if shellNames[base] {
+			continue
+		}
+		if sessionNames[base] {
+			return victim{}, fmt.Errorf("%w: %s (PID %d) was started from %s (PID %d), not by a supervisor; kill it without --parent",
+				domain.ErrNoSupervisor, name, v.PID, a.Name, a.PID)
+		}
+		return victim{PID: a.PID, Process: a}, nil
+	}
+	return victim{}, fmt.Errorf("%w: %s (PID %d) has no ancestor other than shells below PID 1",
+		domain.ErrNoSupervisor, name, v.PID)
+}
+
+// revalidate checks that the targets which are not among the socket's
+// holders are still the processes that were chosen, by create time.
+func (s *KillByPortService) revalidate(ctx context.Context, targets, holders []victim) error {
+	var extra []domain.PortBinding
+	var chosen []*domain.ProcessIdentity
+	for _, t := range targets {
+		if slices.ContainsFunc(holders, func(h victim) bool { return h.PID == t.PID }) {
+			continue
+		}
+		if t.Process == nil || t.Process.CreateTimeMs == 0 {
+			return fmt.Errorf("cannot revalidate PID %d before termination: %w", t.PID, domain.ErrOwnershipConflict)
+		}
+		extra = append(extra, domain.PortBinding{PID: t.PID})
+		chosen = append(chosen, t.Process)
+	}
+	if len(extra) == 0 {
+		return nil
+	}
+	current, err := s.resolver.Enrich(ctx, extra)
+	if err != nil || len(current) != len(extra) {
+		return fmt.Errorf("cannot revalidate process identity before termination: %w", domain.ErrOwnershipConflict)
+	}
+	for i := range current {
+		if !chosen[i].MatchesIdentity(current[i].Process) {
+			return fmt.Errorf("%w: PID %d exited or was reused since it was chosen", domain.ErrOwnershipConflict, chosen[i].PID)
+		}
+	}
+	return nil
+}
+
+func needsIdentityCheck(victims []victim) bool {
+	for _, v := range victims {
+		if v.Process != nil && v.Process.CreateTimeMs > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerIdentities indexes the resolved identity of every holder by PID.
+func ownerIdentities(b *domain.PortBinding) map[int32]*domain.ProcessIdentity {
+	ids := make(map[int32]*domain.ProcessIdentity, len(b.Owners)+1)
+	if b.Process != nil {
+		ids[b.PID] = b.Process
+	}
+	for _, o := range b.Owners {
+		if o.Process != nil {
+			ids[o.PID] = o.Process
+		}
+	}
+	return ids
+}
+
+func containsInt32(s []int32, v int32) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// describeScope renders the protocol, family, port and path parts of a kill
+// filter for error messages, e.g. "tcp/ipv6 port 8080".
+func describeScope(f *domain.Filter) string {
+	var parts []string
+	for _, p := range f.Protocols {
+		parts = append(parts, p.String())
+	}
+	for _, fam := range f.Families {
+		parts = append(parts, fam.String())
+	}
+	desc := strings.Join(parts, "/")
+	if len(f.Ports) > 0 {
+		if desc != "" {
+			desc += " "
+		}
+		desc += fmt.Sprintf("port %d", f.Ports[0])
+	}
+	if len(f.Paths) > 0 {
+		if desc != "" {
+			desc += " "
+		}
+		desc += f.Paths[0]
+	}
+	if len(f.Projects) > 0 {
+		if desc != "" {
+			desc += " "
+		}
+		desc += "in " + strings.Join(f.Projects, ", ")
+	}
+	if desc == "" {
+		desc = "filter"
+	}
+	return desc
+}
+
+// unownedError explains why a socket without a PID cannot be killed, or
+// returns nil when the platform did not classify it.
+func unownedError(b *domain.PortBinding) error {
+	switch b.OwnerKind {
+	case domain.OwnerHidden:
+		owner := "another user"
+		if b.Username != "" {
+			owner = "user " + b.Username
+		}
+		return fmt.Errorf("%w: the process holding %s belongs to %s and is hidden; run as root", domain.ErrPermissionDenied, b.LocalAddr(), owner)
+	case domain.OwnerKernel:
+		return fmt.Errorf("%w: %s is held by the kernel, not by a process (e.g. nfsd or WireGuard)", domain.ErrKernelSocket, b.LocalAddr())
+	case domain.OwnerNone:
+		return fmt.Errorf("%w: %s is no longer owned by any process", domain.ErrProcessExited, b.LocalAddr())
+	}
+	return nil
+}
+
+// isSystemd reports whether v is the init process running systemd, which
+// holds socket-activated listeners on behalf of .socket units.
+func isSystemd(v victim) bool {
+	return v.PID == 1 && v.Process != nil && v.Process.Name == "systemd"
+}
+
+// socketActivatedError explains that a socket belongs to systemd socket
+// activation and how to release it instead.
+func socketActivatedError(target *domain.PortBinding, res *ports.TerminateResult) error {
+	if target.SocketUnit == "" {
+		res.BlockedBy = "systemd socket activation"
+		return fmt.Errorf("%w: owned by systemd for a .socket unit; find it with systemctl list-sockets and stop that unit",
+			domain.ErrSocketActivated)
+	}
+	res.BlockedBy = "systemd " + target.SocketUnit
+	return fmt.Errorf("%w: owned by systemd for %s; use systemctl stop %s",
+		domain.ErrSocketActivated, target.SocketUnit, target.SocketUnit)
+}
+
 func isCritical(pid int32, proc *domain.ProcessIdentity) bool {
 	if criticalPIDs[pid] {
 		return true
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"math"
 	"sort"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
@@ -16,8 +17,19 @@ const (
 	SortByPID
 	SortByName
 	SortByProtocol
+
+	// Resource keys put the heaviest or longest-running owners first.
+	SortByCPU
+	SortByMemory
+	SortByFDs
+	SortByUptime
 )
 
+// NeedsUsage reports whether sorting by f needs ProcessIdentity.Usage.
+func (f SortField) NeedsUsage() bool {
+	return f == SortByCPU || f == SortByMemory || f == SortByFDs
+}
+
 // ListPortsService enumerates and enriches port bindings.
 type ListPortsService struct {
 	enumerator ports.Enumerator
@@ -29,7 +41,8 @@ func NewListPortsService(e ports.Enumerator, r ports.ProcessResolver) *ListPorts
 	return &ListPortsService{enumerator: e, resolver: r}
 }
 
-// List enumerates ports, enriches with process info, and applies sorting.
+// List enumerates ports, enriches with process info, applies the
+// process-level part of filter, and sorts the result.
 func (s *ListPortsService) List(ctx context.Context, filter *domain.Filter, sortBy SortField) (*domain.PartialResult[[]domain.PortBinding], error) {
 	result, err := s.enumerator.List(ctx, filter)
 	if err != nil {
@@ -43,6 +56,16 @@ func (s *ListPortsService) List(ctx context.Context, filter *domain.Filter, sort
 		result.Data = enriched
 	}
 
+	if filter != nil {
+		kept := result.Data[:0]
+		for i := range result.Data {
+			if filter.MatchesProcess(&result.Data[i]) {
+				kept = append(kept, result.Data[i])
+			}
+		}
+		result.Data = kept
+	}
+
 	sortBindings(result.Data, sortBy)
 	return result, nil
 }
@@ -60,12 +83,46 @@ func sortBindings(bindings []domain.PortBinding, by SortField) {
 				return bindings[i].Protocol < bindings[j].Protocol
 			}
 			return bindings[i].LocalPort < bindings[j].LocalPort
+		case SortByCPU, SortByMemory, SortByFDs, SortByUptime:
+			ui, uj := usageKey(&bindings[i], by), usageKey(&bindings[j], by)
+			if ui != uj {
+				return ui > uj
+			}
+			return bindings[i].LocalPort < bindings[j].LocalPort
 		default: // SortByPort
 			return bindings[i].LocalPort < bindings[j].LocalPort
 		}
 	})
 }
 
+// usageKey is the value of a resource sort key for pb's owner, larger
+// meaning heavier or older, and -Inf when it was not measured so those
+// sort last.
+func usageKey(pb *domain.PortBinding, by SortField) float64 {
+	p := pb.Process
+	if p == nil {
+		return math.Inf(-1)
+	}
+	if by == SortByUptime {
+		if p.CreateTimeMs == 0 {
+			return math.Inf(-1)
+		}
+		return -float64(p.CreateTimeMs)
+	}
+	u := p.Usage
+	if u == nil {
+		return math.Inf(-1)
+	}
+	switch by {
+	case SortByCPU:
+		return u.CPUPercent
+	case SortByMemory:
+		return float64(u.RSS)
+	default: // SortByFDs
+		return float64(u.FDs)
+	}
+}
+
 func processName(pb *domain.PortBinding) string {
 	if pb.Process != nil {
 		return pb.Process.Name
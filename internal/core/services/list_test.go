@@ -2,6 +2,10 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
@@ -33,13 +37,33 @@ func (f *fakeEnumerator) List(_ context.Context, filter *domain.Filter) (*domain
 	return &domain.PartialResult[[]domain.PortBinding]{Data: data}, nil
 }
 
-type fakeResolver struct{}
+// fakeResolver enriches the holders of each socket with their identity in
+// procs, or a placeholder named fake-process for PIDs missing from it, and
+// attributes every socket to socketUnit.
+type fakeResolver struct {
+	procs      map[int32]domain.ProcessIdentity
+	socketUnit string
+}
+
+func (f *fakeResolver) identity(pid int32) domain.ProcessIdentity {
+	id, ok := f.procs[pid]
+	if !ok {
+		id.Name = "fake-process"
+	}
+	id.PID = pid
+	return id
+}
 
 func (f *fakeResolver) Enrich(_ context.Context, bindings []domain.PortBinding) ([]domain.PortBinding, error) {
 	for i := range bindings {
-		bindings[i].Process = &domain.ProcessIdentity{
-			PID: bindings[i].PID, Name: "fake-process",
+		b := &bindings[i]
+		id := f.identity(b.PID)
+		b.Process = &id
+		for j := range b.Owners {
+			id := f.identity(b.Owners[j].PID)
+			b.Owners[j].Process = &id
 		}
+		b.SocketUnit = f.socketUnit
 	}
 	return bindings, nil
 }
@@ -110,6 +134,107 @@ func TestListPorts_Enrichment(t *testing.T) {
 	}
 }
 
+func TestListPorts_FilterByContainer(t *testing.T) {
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {Name: "node"},
+		200: {Name: "node", ContainerRuntime: "docker", ContainerID: "3f2a1b4c5d6e7f80"},
+		300: {Name: "node"},
+	}}
+	svc := services.NewListPortsService(&fakeEnumerator{bindings: sampleBindings()}, resolver)
+
+	for _, c := range []string{"3f2a1b", "docker"} {
+		result, err := svc.List(context.Background(), &domain.Filter{Containers: []string{c}}, services.SortByPort)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Data) != 1 || result.Data[0].PID != 200 {
+			t.Errorf("--container %s: expected only PID 200, got %+v", c, result.Data)
+		}
+	}
+
+	result, err := svc.List(context.Background(), &domain.Filter{Containers: []string{"podman"}}, services.SortByPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != 0 {
+		t.Errorf("expected no podman bindings, got %d", len(result.Data))
+	}
+}
+
+func TestListPorts_SortByCPUPutsBusiestFirst(t *testing.T) {
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {Name: "node", Usage: &domain.ProcessUsage{CPUPercent: 2.5, FDs: -1}},
+		200: {Name: "node"},
+		300: {Name: "node", Usage: &domain.ProcessUsage{CPUPercent: 97, FDs: -1}},
+	}}
+	svc := services.NewListPortsService(&fakeEnumerator{bindings: sampleBindings()}, resolver)
+
+	result, err := svc.List(context.Background(), nil, services.SortByCPU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pids []int32
+	for _, b := range result.Data {
+		pids = append(pids, b.PID)
+	}
+	if !slices.Equal(pids, []int32{300, 100, 200}) {
+		t.Errorf("sorted by CPU: got PIDs %v, want [300 100 200] (unmeasured last)", pids)
+	}
+}
+
+func TestListPorts_FilterByProject(t *testing.T) {
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {Name: "node", Cwd: "/src/shop/web"},
+		200: {Name: "node", Cwd: "/src/shopping"},
+		300: {Name: "node", Cwd: "/src/shop"},
+	}}
+	svc := services.NewListPortsService(&fakeEnumerator{bindings: sampleBindings()}, resolver)
+
+	result, err := svc.List(context.Background(), &domain.Filter{Projects: []string{"/src/shop"}}, services.SortByPID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pids []int32
+	for _, b := range result.Data {
+		pids = append(pids, b.PID)
+	}
+	if !slices.Equal(pids, []int32{100, 300}) {
+		t.Errorf("--project /src/shop: got PIDs %v, want [100 300]", pids)
+	}
+}
+
+func TestListPorts_FilterByApp(t *testing.T) {
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {AppLabel: "java:com.acme.ApiServer"},
+		200: {AppLabel: "python:uvicorn app.main:app"},
+		300: {AppLabel: "go:./bin/mongo-proxy"},
+	}}
+	svc := services.NewListPortsService(&fakeEnumerator{bindings: sampleBindings()}, resolver)
+
+	for _, tt := range []struct {
+		apps []string
+		want []int32
+	}{
+		{[]string{"java"}, []int32{100}},
+		{[]string{"uvicorn"}, []int32{200}},
+		{[]string{"Go"}, []int32{300}},
+		{[]string{"node:server"}, nil},
+		{[]string{"python:uv", "acme"}, []int32{100, 200}},
+	} {
+		result, err := svc.List(context.Background(), &domain.Filter{Apps: tt.apps}, services.SortByPID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pids []int32
+		for _, b := range result.Data {
+			pids = append(pids, b.PID)
+		}
+		if !slices.Equal(pids, tt.want) {
+			t.Errorf("--app %v: got PIDs %v, want %v", tt.apps, pids, tt.want)
+		}
+	}
+}
+
 // --- Kill service tests ---
 
 type fakeTerminator struct {
@@ -180,3 +305,339 @@ func TestKillByPort_NotFound(t *testing.T) {
 		t.Error("expected not found error")
 	}
 }
+
+func TestKillMatching_UnixSocketPath(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.Unix, Path: "/run/other.sock", PID: 100, State: domain.StateListen},
+		{Protocol: domain.Unix, Path: "/run/api.sock", PID: 200, State: domain.StateListen},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, &fakeResolver{}, term)
+
+	scope := &domain.Filter{Protocols: []domain.Protocol{domain.Unix}, Paths: []string{"/run/api.sock"}}
+	result, err := svc.KillMatching(context.Background(), scope, ports.SignalPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Path != "/run/api.sock" {
+		t.Errorf("expected path /run/api.sock, got %q", result.Path)
+	}
+	if len(term.terminated) != 1 || term.terminated[0] != 200 {
+		t.Errorf("expected PID 200 terminated, got %v", term.terminated)
+	}
+}
+
+func sharedListener(pids ...int32) []domain.PortBinding {
+	owners := make([]domain.SocketOwner, len(pids))
+	for i, p := range pids {
+		owners[i] = domain.SocketOwner{PID: p, FDs: []int32{3}}
+	}
+	return []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 80, PID: pids[0], Owners: owners, State: domain.StateListen},
+	}
+}
+
+func TestKillByPort_SharedSocketTargetsCommonParent(t *testing.T) {
+	enum := &fakeEnumerator{bindings: sharedListener(100, 101, 102)}
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {PPID: 1, Name: "worker"}, 101: {PPID: 100, Name: "worker"}, 102: {PPID: 100, Name: "worker"},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	result, err := svc.Kill(context.Background(), 80, domain.TCP, ports.SignalPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PID != 100 {
+		t.Errorf("expected master PID 100, got %d", result.PID)
+	}
+	if len(term.terminated) != 1 || term.terminated[0] != 100 {
+		t.Errorf("expected only PID 100 terminated, got %v", term.terminated)
+	}
+}
+
+func TestKillByPort_SharedSocketWithoutParentIsRefused(t *testing.T) {
+	enum := &fakeEnumerator{bindings: sharedListener(100, 101)}
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {PPID: 50, Name: "worker"}, 101: {PPID: 50, Name: "worker"},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	_, err := svc.Kill(context.Background(), 80, domain.TCP, ports.SignalPolicy{})
+	if !errors.Is(err, domain.ErrSharedSocket) {
+		t.Fatalf("expected ErrSharedSocket, got %v", err)
+	}
+	if len(term.terminated) != 0 {
+		t.Errorf("expected nothing terminated, got %v", term.terminated)
+	}
+
+	result, err := svc.Kill(context.Background(), 80, domain.TCP, ports.SignalPolicy{AllOwners: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.PIDs) != 2 || len(term.terminated) != 2 {
+		t.Errorf("expected both owners terminated, got %v", term.terminated)
+	}
+}
+
+func TestKillByPort_SocketActivatedExplainsUnit(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 8080, PID: 1, State: domain.StateListen},
+	}}
+	term := &fakeTerminator{}
+	resolver := &fakeResolver{
+		procs:      map[int32]domain.ProcessIdentity{1: {Name: "systemd", SystemdUnit: "init.scope"}},
+		socketUnit: "api.socket",
+	}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	res, err := svc.Kill(context.Background(), 8080, domain.TCP, ports.SignalPolicy{})
+	if !errors.Is(err, domain.ErrSocketActivated) {
+		t.Fatalf("expected ErrSocketActivated, got %v", err)
+	}
+	want := "owned by systemd for api.socket; use systemctl stop api.socket"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q in error, got %q", want, err.Error())
+	}
+	if !res.Blocked || len(term.terminated) != 0 {
+		t.Errorf("expected blocked result without termination, got %+v", res)
+	}
+}
+
+func TestKillByPort_HiddenOwnerIsPermissionError(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 5432, State: domain.StateListen,
+			OwnerKind: domain.OwnerHidden, UID: 26, HasUID: true, Username: "postgres"},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, &fakeResolver{}, term)
+
+	_, err := svc.Kill(context.Background(), 5432, domain.TCP, ports.SignalPolicy{})
+	if !errors.Is(err, domain.ErrPermissionDenied) {
+		t.Fatalf("expected ErrPermissionDenied, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "postgres") {
+		t.Errorf("expected socket owner in error, got %q", err.Error())
+	}
+	if len(term.terminated) != 0 {
+		t.Errorf("expected no termination, got %v", term.terminated)
+	}
+}
+
+// treeResolver adds a process tree to fakeResolver. Identities carry a
+// create time derived from the PID, except for PIDs in reused once they have
+// been looked up.
+type treeResolver struct {
+	fakeResolver
+	ancestors   map[int32][]int32
+	descendants map[int32][]int32
+	reused      map[int32]bool
+	seen        map[int32]bool
+}
+
+func (r *treeResolver) identity(pid int32) domain.ProcessIdentity {
+	id := r.fakeResolver.identity(pid)
+	id.CreateTimeMs = int64(pid) * 1000
+	if r.reused[pid] && r.seen[pid] {
+		id.CreateTimeMs++
+	}
+	if r.seen == nil {
+		r.seen = make(map[int32]bool)
+	}
+	r.seen[pid] = true
+	return id
+}
+
+func (r *treeResolver) Enrich(_ context.Context, bindings []domain.PortBinding) ([]domain.PortBinding, error) {
+	for i := range bindings {
+		id := r.identity(bindings[i].PID)
+		bindings[i].Process = &id
+	}
+	return bindings, nil
+}
+
+func (r *treeResolver) Ancestors(_ context.Context, pid int32) ([]domain.ProcessIdentity, error) {
+	var out []domain.ProcessIdentity
+	for _, p := range r.ancestors[pid] {
+		out = append(out, r.identity(p))
+	}
+	return out, nil
+}
+
+func (r *treeResolver) Descendants(_ context.Context, pid int32) ([]domain.ProcessIdentity, error) {
+	var out []domain.ProcessIdentity
+	for _, p := range r.descendants[pid] {
+		out = append(out, r.identity(p))
+	}
+	return out, nil
+}
+
+// exitedTerminator reports the PIDs in exited as already gone.
+type exitedTerminator struct {
+	fakeTerminator
+	exited map[int32]bool
+}
+
+func (f *exitedTerminator) Terminate(ctx context.Context, pid int32, policy ports.SignalPolicy) error {
+	if f.exited[pid] {
+		return domain.ErrProcessExited
+	}
+	return f.fakeTerminator.Terminate(ctx, pid, policy)
+}
+
+// namedProcs is a fakeResolver knowing only the names of processes.
+func namedProcs(names map[int32]string) fakeResolver {
+	procs := make(map[int32]domain.ProcessIdentity, len(names))
+	for pid, name := range names {
+		procs[pid] = domain.ProcessIdentity{Name: name}
+	}
+	return fakeResolver{procs: procs}
+}
+
+func devServer() *treeResolver {
+	return &treeResolver{
+		fakeResolver: namedProcs(map[int32]string{300: "node", 200: "sh", 100: "npm", 50: "zsh"}),
+		ancestors:    map[int32][]int32{300: {200, 100, 50}},
+		descendants:  map[int32][]int32{100: {200, 300, 301}, 300: {301}},
+	}
+}
+
+func TestKillByPort_ParentTargetsNearestNonShellAncestor(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 3000, PID: 300, State: domain.StateListen},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, devServer(), term)
+
+	result, err := svc.Kill(context.Background(), 3000, domain.TCP, ports.SignalPolicy{Parent: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(term.terminated, []int32{100}) {
+		t.Errorf("terminated %v, want npm (100) only", term.terminated)
+	}
+	if result.Holder == nil || result.Holder.PID != 300 {
+		t.Errorf("result.Holder = %+v, want node (300)", result.Holder)
+	}
+}
+
+func TestKillByPort_ParentRefusesSessionProcess(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 3000, PID: 300, State: domain.StateListen},
+	}}
+	resolver := &treeResolver{
+		fakeResolver: namedProcs(map[int32]string{300: "node", 200: "-zsh", 100: "sshd"}),
+		ancestors:    map[int32][]int32{300: {200, 100}},
+	}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	_, err := svc.Kill(context.Background(), 3000, domain.TCP, ports.SignalPolicy{Parent: true})
+	if !errors.Is(err, domain.ErrNoSupervisor) {
+		t.Fatalf("expected ErrNoSupervisor, got %v", err)
+	}
+	if len(term.terminated) != 0 {
+		t.Errorf("terminated %v, want nothing", term.terminated)
+	}
+}
+
+func TestKillByPort_TreeKillsTargetFirstAndToleratesExitedChildren(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 3000, PID: 300, State: domain.StateListen},
+	}}
+	term := &exitedTerminator{exited: map[int32]bool{200: true}}
+	svc := services.NewKillByPortService(enum, devServer(), term)
+
+	result, err := svc.Kill(context.Background(), 3000, domain.TCP, ports.SignalPolicy{Parent: true, Tree: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(result.PIDs, []int32{100, 200, 300, 301}) {
+		t.Errorf("targeted %v, want [100 200 300 301]", result.PIDs)
+	}
+	if !slices.Equal(term.terminated, []int32{100, 300, 301}) {
+		t.Errorf("terminated %v, want [100 300 301] with 200 already gone", term.terminated)
+	}
+}
+
+func TestKillByPort_ParentRevalidatesAncestor(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 3000, PID: 300, State: domain.StateListen},
+	}}
+	resolver := devServer()
+	resolver.reused = map[int32]bool{100: true}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	_, err := svc.Kill(context.Background(), 3000, domain.TCP, ports.SignalPolicy{Parent: true})
+	if !errors.Is(err, domain.ErrOwnershipConflict) {
+		t.Fatalf("expected ErrOwnershipConflict for a reused ancestor PID, got %v", err)
+	}
+	if len(term.terminated) != 0 {
+		t.Errorf("terminated %v, want nothing", term.terminated)
+	}
+}
+
+func TestKillByPort_TreeNeedsProcessTree(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, LocalPort: 3000, PID: 300, State: domain.StateListen},
+	}}
+	svc := services.NewKillByPortService(enum, &fakeResolver{}, &fakeTerminator{})
+
+	if _, err := svc.Kill(context.Background(), 3000, domain.TCP, ports.SignalPolicy{Tree: true}); !errors.Is(err, domain.ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestKillMatching_ProjectPicksListenerFromCheckout(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4(127, 0, 0, 1), LocalPort: 3000, PID: 100, State: domain.StateListen},
+		{Protocol: domain.TCP, Family: domain.FamilyIPv6, LocalIP: net.IPv6loopback, LocalPort: 3000, PID: 200, State: domain.StateListen},
+	}}
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {Name: "node", Cwd: "/src/blog"},
+		200: {Name: "node", Cwd: "/src/shop"},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	scope := &domain.Filter{Ports: []uint16{3000}, Protocols: []domain.Protocol{domain.TCP}, Projects: []string{"/src/shop"}}
+	if _, err := svc.KillMatching(context.Background(), scope, ports.SignalPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(term.terminated, []int32{200}) {
+		t.Errorf("terminated %v, want the listener started in /src/shop (200)", term.terminated)
+	}
+
+	scope.Projects = []string{"/src/docs"}
+	if _, err := svc.KillMatching(context.Background(), scope, ports.SignalPolicy{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a project without listeners, got %v", err)
+	}
+}
+
+func TestKillEach_StopsEveryListenerInProject(t *testing.T) {
+	enum := &fakeEnumerator{bindings: []domain.PortBinding{
+		{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4zero, LocalPort: 8080, PID: 100, State: domain.StateListen},
+		{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4zero, LocalPort: 8081, PID: 100, State: domain.StateListen},
+		{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4zero, LocalPort: 3000, PID: 200, State: domain.StateListen},
+		{Protocol: domain.TCP, Family: domain.FamilyIPv6, LocalIP: net.IPv6unspecified, LocalPort: 5173, PID: 300, State: domain.StateListen},
+	}}
+	resolver := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {Name: "node", Cwd: "/src/shop"},
+		200: {Name: "node", Cwd: "/src/blog"},
+		300: {Name: "node", Cwd: "/src/shop/web"},
+	}}
+	term := &fakeTerminator{}
+	svc := services.NewKillByPortService(enum, resolver, term)
+
+	scope := &domain.Filter{Protocols: []domain.Protocol{domain.TCP}, Projects: []string{"/src/shop"}}
+	results, err := svc.KillEach(context.Background(), scope, ports.SignalPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !slices.Equal(term.terminated, []int32{100, 300}) {
+		t.Errorf("terminated %v in %d kills, want PIDs 100 and 300 once each", term.terminated, len(results))
+	}
+}
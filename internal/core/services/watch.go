@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
 	"github.com/z1j1e/porthog/internal/core/ports"
@@ -21,30 +25,313 @@ const (
 	DiffRemoved
 )
 
-// WatchPortsService provides snapshot and diff capabilities for watch mode.
+// fullEnrichEvery bounds how long identities are reused between snapshots
+// before every binding is enriched afresh. Identities are kept by PID
+// alone, so a resolver caching them for less (see ports.IdentityCache)
+// shortens it: an exec or a reused PID shows up no later than it would
+// without the watch service.
+const fullEnrichEvery = 30 * time.Second
+
+// WatchPortsService provides snapshot, event and diff capabilities for
+// watch mode.
 type WatchPortsService struct {
-	enumerator ports.Enumerator
-	resolver   ports.ProcessResolver
+	watcher  ports.Watcher
+	resolver ports.ProcessResolver
+	procs    ports.ProcessMonitor
+
+	// Enrichment carried over from the previous snapshot, so each tick only
+	// resolves processes that were not running at the last one.
+	mu         sync.Mutex
+	identities map[int32]*domain.ProcessIdentity
+	users      map[uint32]string
+	fullAt     time.Time
+
+	// PIDs owning a socket in the latest snapshot or added since.
+	owners map[int32]bool
 }
 
-// NewWatchPortsService creates a new WatchPortsService.
+// NewWatchPortsService creates a WatchPortsService that polls e.
 func NewWatchPortsService(e ports.Enumerator, r ports.ProcessResolver) *WatchPortsService {
-	return &WatchPortsService{enumerator: e, resolver: r}
+	return NewWatchPortsServiceWithWatcher(&enumeratorWatcher{enum: e}, r)
+}
+
+// NewWatchPortsServiceWithWatcher creates a WatchPortsService whose
+// snapshots and events come from w.
+func NewWatchPortsServiceWithWatcher(w ports.Watcher, r ports.ProcessResolver) *WatchPortsService {
+	return &WatchPortsService{watcher: w, resolver: r}
+}
+
+// SetProcessMonitor makes OwnerChanges use m.
+func (s *WatchPortsService) SetProcessMonitor(m ports.ProcessMonitor) {
+	s.procs = m
 }
 
 // Snapshot returns the current state of all port bindings.
 func (s *WatchPortsService) Snapshot(ctx context.Context, filter *domain.Filter) (*ports.Snapshot, error) {
-	result, err := s.enumerator.List(ctx, filter)
+	snap, err := s.watcher.Snapshot(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	enriched, _ := s.resolver.Enrich(ctx, result.Data)
-	if enriched != nil {
-		result.Data = enriched
+	snap.Bindings = s.enrich(ctx, snap.Bindings)
+	if filter.HasProcessCriteria() {
+		snap.Bindings = slices.DeleteFunc(snap.Bindings, func(b domain.PortBinding) bool {
+			return !filter.MatchesProcess(&b)
+		})
+	}
+	return snap, nil
+}
+
+// Events streams the watcher's events, enriched like snapshots. When the
+// watcher cannot observe changes its error is returned and callers should
+// keep polling Snapshot. New sockets failing the filter's process criteria
+// are dropped; removals always pass, as their processes may be gone.
+func (s *WatchPortsService) Events(ctx context.Context, filter *domain.Filter) (<-chan ports.WatchEvent, error) {
+	in, err := s.watcher.Events(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan ports.WatchEvent)
+	go func() {
+		defer close(out)
+		for ev := range in {
+			ev = s.enrichEvent(ctx, ev)
+			if ev.Type == ports.WatchAdded && !filter.MatchesProcess(&ev.Binding) {
+				continue
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// OwnerChanges drops the cached identity of every process the monitor sees
+// exec or exit, in the resolver as well as here, so a reused PID or a new
+// program image is never shown under its old name. The PIDs among them that
+// owned a socket, whose rows are now stale, are sent on the returned
+// channel. Without a monitor, or when it fails, its error is returned and
+// identities are left to expire.
+func (s *WatchPortsService) OwnerChanges(ctx context.Context) (<-chan int32, error) {
+	if s.procs == nil {
+		return nil, fmt.Errorf("%w: no process monitor", domain.ErrUnsupported)
+	}
+	in, err := s.procs.Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan int32)
+	go func() {
+		defer close(out)
+		for ev := range in {
+			if !s.forget(ev) {
+				continue
+			}
+			select {
+			case out <- ev.PID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// forget invalidates the process ev is about and reports whether it owned
+// a socket.
+func (s *WatchPortsService) forget(ev ports.ProcessEvent) bool {
+	s.resolver.InvalidatePID(ev.PID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.identities, ev.PID)
+	owned := s.owners[ev.PID]
+	if ev.Type == ports.ProcessExit {
+		delete(s.owners, ev.PID)
+	}
+	return owned
+}
+
+// enrichEvent fills in an event's binding from the identities already
+// known. New sockets that need the resolver go to it; removed ones do not,
+// since their processes have often exited along with them.
+func (s *WatchPortsService) enrichEvent(ctx context.Context, ev ports.WatchEvent) ports.WatchEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := &ev.Binding
+	if s.fillKnown(b) || ev.Type == ports.WatchRemoved {
+		return ev
+	}
+	if enriched, _ := s.resolver.Enrich(ctx, []domain.PortBinding{*b}); len(enriched) == 1 {
+		*b = enriched[0]
+	}
+	if s.identities == nil {
+		s.identities = make(map[int32]*domain.ProcessIdentity)
+		s.users = make(map[uint32]string)
+	}
+	remember(s.identities, s.users, b)
+	if s.owners == nil {
+		s.owners = make(map[int32]bool)
+	}
+	for _, pid := range b.OwnerPIDs() {
+		s.owners[pid] = true
+	}
+	return ev
+}
+
+// enrich fills in bindings whose owners were all seen by the previous
+// snapshot from its identities and sends only the rest to the resolver.
+// PIDs absent from a snapshot are forgotten, so a reused PID is resolved
+// again.
+func (s *WatchPortsService) enrich(ctx context.Context, bindings []domain.PortBinding) []domain.PortBinding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.fullAt) >= s.reuseFor() {
+		s.identities, s.users = nil, nil
+		s.fullAt = time.Now()
+	}
+
+	var pending []domain.PortBinding
+	var pendingIdx []int
+	for i := range bindings {
+		if !s.fillKnown(&bindings[i]) {
+			pending = append(pending, bindings[i])
+			pendingIdx = append(pendingIdx, i)
+		}
+	}
+	if len(pending) > 0 {
+		if enriched, _ := s.resolver.Enrich(ctx, pending); enriched != nil {
+			for j, i := range pendingIdx {
+				bindings[i] = enriched[j]
+			}
+		}
+	}
+
+	if sampler, ok := s.resolver.(ports.UsageSampler); ok {
+		withUsage(bindings, sampler.SampleUsage(ctx, ownerPIDs(bindings)))
+	}
+
+	identities := make(map[int32]*domain.ProcessIdentity)
+	users := make(map[uint32]string)
+	owners := make(map[int32]bool)
+	for i := range bindings {
+		remember(identities, users, &bindings[i])
+		for _, pid := range bindings[i].OwnerPIDs() {
+			owners[pid] = true
+		}
+	}
+	s.identities, s.users, s.owners = identities, users, owners
+	return bindings
+}
+
+// reuseFor is how long identities are carried over between snapshots.
+func (s *WatchPortsService) reuseFor() time.Duration {
+	if c, ok := s.resolver.(ports.IdentityCache); ok {
+		return min(fullEnrichEvery, c.CacheTTL())
+	}
+	return fullEnrichEvery
+}
+
+// ownerPIDs lists every process holding one of bindings once.
+func ownerPIDs(bindings []domain.PortBinding) []int32 {
+	seen := make(map[int32]bool)
+	var pids []int32
+	for i := range bindings {
+		for _, pid := range bindings[i].OwnerPIDs() {
+			if pid > 0 && !seen[pid] {
+				seen[pid] = true
+				pids = append(pids, pid)
+			}
+		}
+	}
+	return pids
+}
+
+// withUsage attaches the latest usage to the identities of bindings'
+// holders. Identities are shared with earlier snapshots, so they are
+// copied rather than updated in place.
+func withUsage(bindings []domain.PortBinding, usage map[int32]*domain.ProcessUsage) {
+	attach := func(pid int32, p **domain.ProcessIdentity) {
+		if u := usage[pid]; u != nil && *p != nil {
+			cp := **p
+			cp.Usage = u
+			*p = &cp
+		}
+	}
+	for i := range bindings {
+		b := &bindings[i]
+		attach(b.PID, &b.Process)
+		for j := range b.Owners {
+			attach(b.Owners[j].PID, &b.Owners[j].Process)
+		}
+	}
+}
+
+// remember records the identities and username b was enriched with.
+func remember(identities map[int32]*domain.ProcessIdentity, users map[uint32]string, b *domain.PortBinding) {
+	if b.Process != nil && !b.Process.PermissionDenied {
+		identities[b.PID] = b.Process
+	}
+	for _, o := range b.Owners {
+		if o.Process != nil && !o.Process.PermissionDenied {
+			identities[o.PID] = o.Process
+		}
+	}
+	if b.HasUID && b.Username != "" {
+		users[b.UID] = b.Username
+	}
+}
+
+// fillKnown enriches b from the previous snapshot and reports whether that
+// covered everything the resolver would have filled in. Sockets held by
+// PID 1 may be systemd socket units, which only the resolver attributes.
+func (s *WatchPortsService) fillKnown(b *domain.PortBinding) bool {
+	pids := b.OwnerPIDs()
+	for _, pid := range pids {
+		if pid == 1 || s.identities[pid] == nil {
+			return false
+		}
+	}
+	var username string
+	if b.HasUID {
+		var ok bool
+		if username, ok = s.users[b.UID]; !ok {
+			return false
+		}
+	}
+
+	b.Username = username
+	if b.PID != 0 {
+		cp := *s.identities[b.PID]
+		b.Process = &cp
+	}
+	for j := range b.Owners {
+		cp := *s.identities[b.Owners[j].PID]
+		b.Owners[j].Process = &cp
+	}
+	return true
+}
+
+// enumeratorWatcher polls an enumerator and has no events.
+type enumeratorWatcher struct {
+	enum ports.Enumerator
+}
+
+func (w *enumeratorWatcher) Snapshot(ctx context.Context, filter *domain.Filter) (*ports.Snapshot, error) {
+	result, err := w.enum.List(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 	return &ports.Snapshot{Bindings: result.Data, Partial: result.Partial}, nil
 }
 
+func (w *enumeratorWatcher) Events(context.Context, *domain.Filter) (<-chan ports.WatchEvent, error) {
+	return nil, domain.ErrUnsupported
+}
+
 // Diff computes the difference between two snapshots.
 func Diff(prev, curr *ports.Snapshot) []DiffEntry {
 	prevMap := bindingKey(prev.Bindings)
@@ -64,16 +351,25 @@ func Diff(prev, curr *ports.Snapshot) []DiffEntry {
 	return diffs
 }
 
+// portKey identifies a binding across snapshots. The family and local
+// address keep a dual-stack pair (or two addresses on the same port) apart;
+// the path does the same for Unix sockets, which have no port.
 type portKey struct {
-	proto domain.Protocol
-	port  uint16
-	pid   int32
+	proto  domain.Protocol
+	family domain.AddressFamily
+	ip     string
+	port   uint16
+	path   string
+	pid    int32
 }
 
 func bindingKey(bindings []domain.PortBinding) map[portKey]domain.PortBinding {
 	m := make(map[portKey]domain.PortBinding, len(bindings))
 	for _, b := range bindings {
-		k := portKey{proto: b.Protocol, port: b.LocalPort, pid: b.PID}
+		k := portKey{proto: b.Protocol, family: b.Family, port: b.LocalPort, path: b.Path, pid: b.PID}
+		if b.LocalIP != nil {
+			k.ip = domain.NormalizeIP(b.LocalIP).String()
+		}
 		m[k] = b
 	}
 	return m
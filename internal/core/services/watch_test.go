@@ -0,0 +1,313 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
+	"github.com/z1j1e/porthog/internal/core/services"
+)
+
+// sequenceEnumerator returns a fresh copy of the next listing on each call.
+type sequenceEnumerator struct {
+	listings [][]domain.PortBinding
+}
+
+func (f *sequenceEnumerator) List(_ context.Context, _ *domain.Filter) (*domain.PartialResult[[]domain.PortBinding], error) {
+	data := slices.Clone(f.listings[0])
+	if len(f.listings) > 1 {
+		f.listings = f.listings[1:]
+	}
+	return &domain.PartialResult[[]domain.PortBinding]{Data: data}, nil
+}
+
+// countingResolver records which PIDs it was asked to enrich and which it
+// was told to forget.
+type countingResolver struct {
+	fakeResolver
+	enriched    []int32
+	invalidated []int32
+}
+
+func (r *countingResolver) InvalidatePID(pid int32) {
+	r.invalidated = append(r.invalidated, pid)
+}
+
+func (r *countingResolver) Enrich(ctx context.Context, bindings []domain.PortBinding) ([]domain.PortBinding, error) {
+	for _, b := range bindings {
+		r.enriched = append(r.enriched, b.PID)
+	}
+	return r.fakeResolver.Enrich(ctx, bindings)
+}
+
+func TestWatchSnapshot_EnrichesOnlyNewPIDs(t *testing.T) {
+	a := domain.PortBinding{Protocol: domain.TCP, LocalPort: 8080, PID: 100, State: domain.StateListen}
+	b := domain.PortBinding{Protocol: domain.TCP, LocalPort: 3000, PID: 200, State: domain.StateListen}
+	c := domain.PortBinding{Protocol: domain.TCP, LocalPort: 9000, PID: 300, State: domain.StateListen}
+	enum := &sequenceEnumerator{listings: [][]domain.PortBinding{
+		{a, b},
+		{a, b, c}, // 300 is new
+		{a},       // 200 goes away...
+		{a, b},    // ...and a process reusing its PID must be resolved again
+	}}
+	res := &countingResolver{}
+	svc := services.NewWatchPortsService(enum, res)
+
+	want := [][]int32{{100, 200}, {300}, nil, {200}}
+	for i, w := range want {
+		res.enriched = nil
+		snap, err := svc.Snapshot(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(res.enriched, w) {
+			t.Errorf("snapshot %d enriched %v, want %v", i, res.enriched, w)
+		}
+		for _, pb := range snap.Bindings {
+			if pb.Process == nil || pb.Process.Name != "fake-process" {
+				t.Errorf("snapshot %d: binding on port %d not enriched", i, pb.LocalPort)
+			}
+		}
+	}
+}
+
+// eventWatcher serves one snapshot and then the scripted events.
+// ttlResolver caches identities for ttl.
+type ttlResolver struct {
+	countingResolver
+	ttl time.Duration
+}
+
+func (r *ttlResolver) CacheTTL() time.Duration { return r.ttl }
+
+func TestWatchSnapshot_ReusesIdentitiesNoLongerThanResolverCaches(t *testing.T) {
+	a := domain.PortBinding{Protocol: domain.TCP, LocalPort: 8080, PID: 100, State: domain.StateListen}
+	enum := &sequenceEnumerator{listings: [][]domain.PortBinding{{a}, {a}}}
+	res := &ttlResolver{ttl: time.Nanosecond}
+	svc := services.NewWatchPortsService(enum, res)
+
+	for range 2 {
+		if _, err := svc.Snapshot(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// PID 100 may have exec'd or been reused; past the resolver's TTL it
+	// must be resolved again rather than shown under its old identity.
+	if !slices.Equal(res.enriched, []int32{100, 100}) {
+		t.Errorf("enriched %v, want PID 100 resolved on both snapshots", res.enriched)
+	}
+}
+
+type eventWatcher struct {
+	snapshot []domain.PortBinding
+	events   []ports.WatchEvent
+}
+
+func (w *eventWatcher) Snapshot(_ context.Context, _ *domain.Filter) (*ports.Snapshot, error) {
+	return &ports.Snapshot{Bindings: slices.Clone(w.snapshot)}, nil
+}
+
+func (w *eventWatcher) Events(_ context.Context, _ *domain.Filter) (<-chan ports.WatchEvent, error) {
+	ch := make(chan ports.WatchEvent, len(w.events))
+	for _, ev := range w.events {
+		ch <- ev
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestWatchEvents_EnrichesNewSocketsOnly(t *testing.T) {
+	known := domain.PortBinding{Protocol: domain.TCP, LocalPort: 8080, PID: 100, State: domain.StateListen}
+	fresh := domain.PortBinding{Protocol: domain.TCP, LocalPort: 3000, PID: 500, State: domain.StateListen}
+	gone := domain.PortBinding{Protocol: domain.TCP, LocalPort: 4000, PID: 900}
+	w := &eventWatcher{
+		snapshot: []domain.PortBinding{known},
+		events: []ports.WatchEvent{
+			{Type: ports.WatchAdded, Binding: known},   // e.g. a second listener of PID 100
+			{Type: ports.WatchAdded, Binding: fresh},   // needs the resolver
+			{Type: ports.WatchRemoved, Binding: fresh}, // known by now
+			{Type: ports.WatchRemoved, Binding: gone},  // exited with its socket
+		},
+	}
+	res := &countingResolver{}
+	svc := services.NewWatchPortsServiceWithWatcher(w, res)
+	if _, err := svc.Snapshot(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	res.enriched = nil
+
+	events, err := svc.Events(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var named []string
+	for ev := range events {
+		name := ""
+		if ev.Binding.Process != nil {
+			name = ev.Binding.Process.Name
+		}
+		named = append(named, name)
+	}
+	if want := []string{"fake-process", "fake-process", "fake-process", ""}; !slices.Equal(named, want) {
+		t.Errorf("event process names %q, want %q", named, want)
+	}
+	if !slices.Equal(res.enriched, []int32{500}) {
+		t.Errorf("resolver asked about %v, want only the new PID 500", res.enriched)
+	}
+}
+
+func TestWatchEvents_UnsupportedWhenPolling(t *testing.T) {
+	svc := services.NewWatchPortsService(&sequenceEnumerator{listings: [][]domain.PortBinding{nil}}, &fakeResolver{})
+	if _, err := svc.Events(context.Background(), nil); !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("Events() error = %v, want ErrUnsupported", err)
+	}
+}
+
+// scriptedMonitor replays process events.
+type scriptedMonitor struct {
+	events []ports.ProcessEvent
+}
+
+func (m *scriptedMonitor) Events(context.Context) (<-chan ports.ProcessEvent, error) {
+	ch := make(chan ports.ProcessEvent, len(m.events))
+	for _, ev := range m.events {
+		ch <- ev
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestWatchOwnerChanges_InvalidatesAndReportsOwners(t *testing.T) {
+	a := domain.PortBinding{Protocol: domain.TCP, LocalPort: 8080, PID: 100, State: domain.StateListen}
+	b := domain.PortBinding{Protocol: domain.TCP, LocalPort: 3000, PID: 200, State: domain.StateListen}
+	c := domain.PortBinding{Protocol: domain.TCP, LocalPort: 9000, PID: 300, State: domain.StateListen}
+	enum := &sequenceEnumerator{listings: [][]domain.PortBinding{{a, b, c}, {a, b, c}}}
+	res := &countingResolver{}
+	svc := services.NewWatchPortsService(enum, res)
+	svc.SetProcessMonitor(&scriptedMonitor{events: []ports.ProcessEvent{
+		{Type: ports.ProcessExec, PID: 999}, // owns no socket
+		{Type: ports.ProcessExit, PID: 200},
+		{Type: ports.ProcessExec, PID: 100},
+	}})
+	if _, err := svc.Snapshot(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := svc.OwnerChanges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var owners []int32
+	for pid := range changes {
+		owners = append(owners, pid)
+	}
+	if !slices.Equal(owners, []int32{200, 100}) {
+		t.Errorf("owner changes %v, want [200 100]", owners)
+	}
+	if !slices.Equal(res.invalidated, []int32{999, 200, 100}) {
+		t.Errorf("resolver invalidated %v, want [999 200 100]", res.invalidated)
+	}
+
+	res.enriched = nil
+	if _, err := svc.Snapshot(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(res.enriched, []int32{100, 200}) {
+		t.Errorf("next snapshot enriched %v, want the changed owners [100 200]", res.enriched)
+	}
+}
+
+func TestWatchOwnerChanges_UnsupportedWithoutMonitor(t *testing.T) {
+	svc := services.NewWatchPortsService(&sequenceEnumerator{listings: [][]domain.PortBinding{nil}}, &fakeResolver{})
+	if _, err := svc.OwnerChanges(context.Background()); !errors.Is(err, domain.ErrUnsupported) {
+		t.Errorf("OwnerChanges() error = %v, want ErrUnsupported", err)
+	}
+}
+
+// usageResolver reports a CPU figure that grows with every sample.
+type usageResolver struct {
+	countingResolver
+	samples int
+}
+
+func (r *usageResolver) SampleUsage(_ context.Context, pids []int32) map[int32]*domain.ProcessUsage {
+	r.samples++
+	usage := make(map[int32]*domain.ProcessUsage)
+	for _, pid := range pids {
+		usage[pid] = &domain.ProcessUsage{CPUPercent: float64(10 * r.samples), FDs: -1}
+	}
+	return usage
+}
+
+func TestWatchSnapshot_RefreshesUsageOfKnownProcesses(t *testing.T) {
+	a := domain.PortBinding{Protocol: domain.TCP, LocalPort: 8080, PID: 100, State: domain.StateListen}
+	enum := &sequenceEnumerator{listings: [][]domain.PortBinding{{a}, {a}}}
+	res := &usageResolver{}
+	svc := services.NewWatchPortsService(enum, res)
+
+	first, err := svc.Snapshot(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.enriched = nil
+	second, err := svc.Snapshot(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.enriched) != 0 {
+		t.Errorf("re-enriched %v, want PID 100's identity reused", res.enriched)
+	}
+	if got := second.Bindings[0].Process.Usage; got == nil || got.CPUPercent != 20 {
+		t.Errorf("second snapshot usage = %+v, want the fresh sample", got)
+	}
+	if got := first.Bindings[0].Process.Usage; got == nil || got.CPUPercent != 10 {
+		t.Errorf("first snapshot usage changed to %+v, want it left alone", got)
+	}
+}
+
+func TestWatchSnapshot_FiltersByApp(t *testing.T) {
+	bindings := sampleBindings()
+	enum := &sequenceEnumerator{listings: [][]domain.PortBinding{bindings}}
+	res := &fakeResolver{procs: map[int32]domain.ProcessIdentity{
+		100: {AppLabel: "java:com.acme.ApiServer"}, 200: {AppLabel: "node:server.js"},
+	}}
+	svc := services.NewWatchPortsService(enum, res)
+
+	snap, err := svc.Snapshot(context.Background(), &domain.Filter{Apps: []string{"node"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Bindings) != 1 || snap.Bindings[0].PID != 200 {
+		t.Errorf("--app node: got %v, want only PID 200", snap.Bindings)
+	}
+}
+
+func TestDiff_KeysOnFamilyAddressAndPath(t *testing.T) {
+	v4 := domain.PortBinding{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4zero, LocalPort: 3000, PID: 100}
+	v6 := domain.PortBinding{Protocol: domain.TCP, Family: domain.FamilyIPv6, LocalIP: net.IPv6unspecified, LocalPort: 3000, PID: 100}
+	lo := domain.PortBinding{Protocol: domain.TCP, Family: domain.FamilyIPv4, LocalIP: net.IPv4(127, 0, 0, 1), LocalPort: 3000, PID: 100}
+	sockA := domain.PortBinding{Protocol: domain.Unix, Family: domain.FamilyUnix, Path: "/run/a.sock", PID: 100}
+	sockB := domain.PortBinding{Protocol: domain.Unix, Family: domain.FamilyUnix, Path: "/run/b.sock", PID: 100}
+
+	prev := &ports.Snapshot{Bindings: []domain.PortBinding{v4, sockA}}
+	curr := &ports.Snapshot{Bindings: []domain.PortBinding{v4, v6, lo, sockA, sockB}}
+	diffs := services.Diff(prev, curr)
+	if len(diffs) != 3 {
+		t.Fatalf("got %d diffs, want the IPv6, loopback and second Unix socket added: %+v", len(diffs), diffs)
+	}
+	for _, d := range diffs {
+		if d.Type != services.DiffAdded {
+			t.Errorf("unexpected %v for %s", d.Type, d.Binding.Path)
+		}
+	}
+
+	if diffs := services.Diff(curr, curr); len(diffs) != 0 {
+		t.Errorf("identical snapshots produced %d diffs", len(diffs))
+	}
+}
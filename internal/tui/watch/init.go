@@ -5,10 +5,15 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
 func (m Model) Init() tea.Cmd {
-	return tickCmd(m.interval)
+	if !m.useEvents {
+		return tickCmd(m.interval)
+	}
+	return tea.Batch(tickCmd(m.interval), m.subscribe, m.subscribeOwners)
 }
 
 func tickCmd(d time.Duration) tea.Cmd {
@@ -18,8 +23,41 @@ func tickCmd(d time.Duration) tea.Cmd {
 }
 
 func (m Model) fetchSnapshot() tea.Msg {
+	return m.takeSnapshot(false)
+}
+
+// refreshNow takes a snapshot between ticks.
+func (m Model) refreshNow() tea.Msg {
+	return m.takeSnapshot(true)
+}
+
+func (m Model) takeSnapshot(extra bool) snapshotMsg {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	snap, err := m.svc.Snapshot(ctx, m.filter)
-	return snapshotMsg{snapshot: snap, err: err}
+	return snapshotMsg{snapshot: snap, err: err, extra: extra}
+}
+
+func (m Model) subscribe() tea.Msg {
+	events, err := m.svc.Events(m.ctx, m.filter)
+	return eventsMsg{events: events, err: err}
+}
+
+func waitEvent(events <-chan ports.WatchEvent) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		return watchEventMsg{event: ev, ok: ok}
+	}
+}
+
+func (m Model) subscribeOwners() tea.Msg {
+	changes, err := m.svc.OwnerChanges(m.ctx)
+	return ownersMsg{changes: changes, err: err}
+}
+
+func waitOwner(changes <-chan int32) tea.Cmd {
+	return func() tea.Msg {
+		pid, ok := <-changes
+		return ownerChangedMsg{pid: pid, ok: ok}
+	}
 }
@@ -1,6 +1,7 @@
 package watch
 
 import (
+	"context"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -15,31 +16,75 @@ type tickMsg time.Time
 type snapshotMsg struct {
 	snapshot *ports.Snapshot
 	err      error
+	extra    bool // taken outside the refresh interval
 }
 
+type eventsMsg struct {
+	events <-chan ports.WatchEvent
+	err    error
+}
+
+type watchEventMsg struct {
+	event ports.WatchEvent
+	ok    bool
+}
+
+type ownersMsg struct {
+	changes <-chan int32
+	err     error
+}
+
+type ownerChangedMsg struct {
+	pid int32
+	ok  bool
+}
+
+// maxRecent is how many socket events are listed below the table.
+const maxRecent = 5
+
 // Model is the Bubbletea model for watch mode.
 type Model struct {
 	svc      *services.WatchPortsService
 	filter   *domain.Filter
 	interval time.Duration
 
-	bindings []domain.PortBinding
-	cursor   int
-	search   textinput.Model
+	// Socket events between snapshots, when the platform reports them.
+	ctx       context.Context
+	cancel    context.CancelFunc
+	useEvents bool
+	events    <-chan ports.WatchEvent
+	eventsErr error
+	recent    []ports.WatchEvent
+
+	// Socket owners exec'ing or exiting trigger an extra refresh, at most
+	// one at a time.
+	owners         <-chan int32
+	refreshing     bool
+	refreshPending bool
+
+	all       []domain.PortBinding // latest snapshot with events applied
+	bindings  []domain.PortBinding
+	cursor    int
+	detail    bool // show the selected row's process in full
+	search    textinput.Model
 	searching bool
-	width    int
-	height   int
-	err      error
-	quitting bool
+	width     int
+	height    int
+	err       error
+	quitting  bool
 }
 
-// New creates a new watch TUI model.
-func New(svc *services.WatchPortsService, filter *domain.Filter, interval time.Duration) Model {
+// New creates a new watch TUI model. With events set, sockets appearing
+// and disappearing between refreshes are shown as they happen where the
+// platform can report them.
+func New(svc *services.WatchPortsService, filter *domain.Filter, interval time.Duration, events bool) Model {
 	ti := textinput.New()
 	ti.Placeholder = "filter..."
 	ti.CharLimit = 64
+	ctx, cancel := context.WithCancel(context.Background())
 	return Model{
 		svc: svc, filter: filter, interval: interval,
+		ctx: ctx, cancel: cancel, useEvents: events,
 		search: ti,
 	}
 }
@@ -7,6 +7,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -23,9 +24,55 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.err = msg.err
 		} else {
-			m.bindings = filterSearch(msg.snapshot.Bindings, m.search.Value())
+			m.all = msg.snapshot.Bindings
+			m.refilter()
 		}
-		return m, tickCmd(m.interval)
+		if !msg.extra {
+			return m, tickCmd(m.interval)
+		}
+		m.refreshing = m.refreshPending
+		m.refreshPending = false
+		if m.refreshing {
+			return m, m.refreshNow
+		}
+		return m, nil
+	case eventsMsg:
+		if msg.err != nil {
+			m.eventsErr = msg.err
+			return m, nil
+		}
+		m.events = msg.events
+		return m, waitEvent(m.events)
+	case watchEventMsg:
+		if !msg.ok {
+			m.events = nil
+			return m, nil
+		}
+		m.all = applyEvent(m.all, msg.event)
+		m.refilter()
+		m.recent = append(m.recent, msg.event)
+		if len(m.recent) > maxRecent {
+			m.recent = m.recent[len(m.recent)-maxRecent:]
+		}
+		return m, waitEvent(m.events)
+	case ownersMsg:
+		// Without process events, identities simply expire.
+		if msg.err != nil {
+			return m, nil
+		}
+		m.owners = msg.changes
+		return m, waitOwner(m.owners)
+	case ownerChangedMsg:
+		if !msg.ok {
+			m.owners = nil
+			return m, nil
+		}
+		if m.refreshing {
+			m.refreshPending = true
+			return m, waitOwner(m.owners)
+		}
+		m.refreshing = true
+		return m, tea.Batch(m.refreshNow, waitOwner(m.owners))
 	}
 
 	if m.searching {
@@ -56,6 +103,7 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
 	case key.Matches(msg, key.NewBinding(key.WithKeys("q", "ctrl+c"))):
 		m.quitting = true
+		m.cancel()
 		return m, tea.Quit
 	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
 		if m.cursor > 0 {
@@ -69,10 +117,42 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.searching = true
 		m.search.Focus()
 		return m, nil
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		m.detail = !m.detail
+	case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		m.detail = false
 	}
 	return m, nil
 }
 
+// refilter recomputes the visible rows and keeps the cursor on them.
+func (m *Model) refilter() {
+	m.bindings = filterSearch(m.all, m.search.Value())
+	if m.cursor >= len(m.bindings) {
+		m.cursor = max(len(m.bindings)-1, 0)
+	}
+}
+
+// applyEvent adds a socket that appeared or drops one that went away.
+func applyEvent(bindings []domain.PortBinding, ev ports.WatchEvent) []domain.PortBinding {
+	b := &ev.Binding
+	kept := make([]domain.PortBinding, 0, len(bindings)+1)
+	for i := range bindings {
+		if !sameSocket(&bindings[i], b) {
+			kept = append(kept, bindings[i])
+		}
+	}
+	if ev.Type == ports.WatchAdded {
+		kept = append(kept, *b)
+	}
+	return kept
+}
+
+func sameSocket(a, b *domain.PortBinding) bool {
+	return a.Protocol == b.Protocol && a.LocalPort == b.LocalPort && a.RemotePort == b.RemotePort &&
+		a.LocalIP.Equal(b.LocalIP) && a.RemoteIP.Equal(b.RemoteIP)
+}
+
 func filterSearch(bindings []domain.PortBinding, query string) []domain.PortBinding {
 	if query == "" {
 		return bindings
@@ -80,11 +160,12 @@ func filterSearch(bindings []domain.PortBinding, query string) []domain.PortBind
 	q := strings.ToLower(query)
 	var filtered []domain.PortBinding
 	for _, b := range bindings {
-		name := ""
+		name, app := "", ""
 		if b.Process != nil {
-			name = b.Process.Name
+			name, app = b.Process.Name, b.Process.AppLabel
 		}
 		if strings.Contains(strings.ToLower(name), q) ||
+			strings.Contains(strings.ToLower(app), q) ||
 			strings.Contains(b.LocalIP.String(), q) {
 			filtered = append(filtered, b)
 		}
@@ -3,8 +3,12 @@ package watch
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/z1j1e/porthog/internal/core/domain"
+	"github.com/z1j1e/porthog/internal/core/ports"
 )
 
 var (
@@ -13,6 +17,9 @@ var (
 	selStyle    = lipgloss.NewStyle().Background(lipgloss.Color("236")).Bold(true)
 	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	errStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	addStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	delStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	detailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
 )
 
 func (m Model) View() string {
@@ -30,14 +37,24 @@ func (m Model) View() string {
 	}
 
 	// Header
-	hdr := fmt.Sprintf("%-5s %-22s %-8s %-20s %-12s", "PROTO", "LOCAL ADDRESS", "PID", "PROCESS", "STATE")
+	hdr := fmt.Sprintf("%-5s %-22s %-8s %-20s %-24s %-12s %6s %9s", "PROTO", "LOCAL ADDRESS", "PID", "PROCESS", "APP", "STATE", "CPU%", "RSS")
 	b.WriteString(lipgloss.NewStyle().Bold(true).Render(hdr))
 	b.WriteString("\n")
-	b.WriteString(strings.Repeat("─", 70))
+	b.WriteString(strings.Repeat("─", 112))
 	b.WriteString("\n")
 
 	// Rows
+	var detail []string
+	if m.detail && m.cursor < len(m.bindings) {
+		detail = detailLines(&m.bindings[m.cursor])
+	}
 	visible := m.height - 6
+	if len(m.recent) > 0 {
+		visible -= len(m.recent) + 1
+	}
+	if len(detail) > 0 {
+		visible -= len(detail) + 1
+	}
 	if visible < 5 {
 		visible = 20
 	}
@@ -48,13 +65,19 @@ func (m Model) View() string {
 
 	for i := start; i < len(m.bindings) && i < start+visible; i++ {
 		pb := m.bindings[i]
-		name := "-"
-		if pb.Process != nil && pb.Process.Name != "" {
-			name = pb.Process.Name
+		name := pb.OwnerName()
+		pid := fmt.Sprintf("%d", pb.PID)
+		if n := len(pb.Owners); n > 1 {
+			pid = fmt.Sprintf("%d+%d", pb.PID, n-1)
+		}
+		app := "-"
+		if pb.Process != nil {
+			app = fitWidth(orDash(pb.Process.AppLabel), 24)
 		}
-		row := fmt.Sprintf("%-5s %-22s %-8d %-20s %-12s",
-			pb.Protocol, fmt.Sprintf("%s:%d", pb.LocalIP, pb.LocalPort),
-			pb.PID, name, pb.State)
+		cpu, rss := usageCells(&pb)
+		row := fmt.Sprintf("%-5s %-22s %-8s %-20s %-24s %-12s %6s %9s",
+			pb.Protocol, pb.LocalAddr(),
+			pid, name, app, pb.State, cpu, rss)
 
 		if i == m.cursor {
 			b.WriteString(selStyle.Render(row))
@@ -69,8 +92,124 @@ func (m Model) View() string {
 		b.WriteString("\n")
 	}
 
+	if len(detail) > 0 {
+		b.WriteString("\n")
+		for _, line := range detail {
+			b.WriteString(detailStyle.Render(fitWidth(line, m.width)))
+			b.WriteString("\n")
+		}
+	}
+
+	if len(m.recent) > 0 {
+		b.WriteString("\n")
+		for _, ev := range m.recent {
+			b.WriteString(eventLine(ev))
+			b.WriteString("\n")
+		}
+	}
+
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("↑/↓ navigate • / search • q quit"))
+	b.WriteString(helpStyle.Render("↑/↓ navigate • enter details • / search • q quit • " + m.mode()))
 
 	return b.String()
 }
+
+// mode tells whether changes show up as they happen or on refresh.
+func (m Model) mode() string {
+	switch {
+	case m.events != nil:
+		return "live"
+	case m.eventsErr != nil:
+		return fmt.Sprintf("refresh every %s (%v)", m.interval, m.eventsErr)
+	default:
+		return fmt.Sprintf("refresh every %s", m.interval)
+	}
+}
+
+func eventLine(ev ports.WatchEvent) string {
+	pb := ev.Binding
+	line := fmt.Sprintf("%s %s %-22s", ev.At.Format("15:04:05.000"), pb.Protocol, pb.LocalAddr())
+	if pb.PID != 0 {
+		line += fmt.Sprintf(" %s (%d)", pb.OwnerName(), pb.PID)
+	}
+	if ev.Type == ports.WatchAdded {
+		return addStyle.Render("+ " + line)
+	}
+	return delStyle.Render("- " + line)
+}
+
+// detailLines describes the process holding pb, command line included.
+func detailLines(pb *domain.PortBinding) []string {
+	p := pb.Process
+	if p == nil {
+		return []string{fmt.Sprintf("PID %d: no process information", pb.PID)}
+	}
+	lines := []string{fmt.Sprintf("PID %d  parent %d  user %s", p.PID, p.PPID, orDash(p.Username))}
+	if p.CreateTimeMs != 0 {
+		line := "started " + p.StartTime().Format("2006-01-02 15:04:05") + "  up " + p.Uptime(time.Now()).Truncate(time.Second).String()
+		if u := p.Usage; u != nil && u.FDs >= 0 {
+			line += fmt.Sprintf("  fds %d", u.FDs)
+		}
+		lines = append(lines, line)
+	}
+	if len(p.Ancestors) > 0 {
+		lines = append(lines, "tree  "+p.Lineage())
+	}
+	if p.Exe != "" {
+		lines = append(lines, "exe  "+p.Exe)
+	}
+	if p.AppLabel != "" {
+		lines = append(lines, "app  "+p.AppLabel)
+	}
+	lines = append(lines, "cmd  "+orDash(p.Cmdline))
+	if p.Cwd != "" {
+		cwd := "cwd  " + p.Cwd
+		if p.ProjectRoot != "" && p.ProjectRoot != p.Cwd {
+			cwd += "  (project " + p.ProjectRoot + ")"
+		}
+		lines = append(lines, cwd)
+	}
+	if p.InContainer() {
+		lines = append(lines, "container  "+p.ContainerRuntime+":"+p.ShortContainerID())
+	}
+	if unit := pb.SocketUnit; unit != "" || p.SystemdUnit != "" {
+		if unit == "" {
+			unit = p.SystemdUnit
+		}
+		lines = append(lines, "unit  "+unit)
+	}
+	return lines
+}
+
+// usageCells renders the CPU and memory columns of the process holding pb.
+func usageCells(pb *domain.PortBinding) (cpu, rss string) {
+	if pb.Process == nil || pb.Process.Usage == nil {
+		return "-", "-"
+	}
+	u := pb.Process.Usage
+	cpu, rss = "-", "-"
+	if u.HasCPU() {
+		cpu = fmt.Sprintf("%.1f", u.CPUPercent)
+	}
+	if u.RSS > 0 {
+		rss = fmt.Sprintf("%.1fM", float64(u.RSS)/(1<<20))
+	}
+	return cpu, rss
+}
+
+// fitWidth cuts s to width columns so it does not wrap, when the width is
+// known.
+func fitWidth(s string, width int) string {
+	r := []rune(s)
+	if width <= 0 || len(r) <= width {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"context"
+	"errors"
 	"net"
 	"strconv"
 	"testing"
@@ -60,3 +61,55 @@ func TestFreePortIsActuallyFree(t *testing.T) {
 	}
 	ln.Close()
 }
+
+func TestFreePortOnInterface(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	port := uint16(busy.Addr().(*net.TCPAddr).Port)
+
+	lo, err := loopbackInterface()
+	if err != nil {
+		t.Skip(err)
+	}
+	svc := services.NewFindFreePortService()
+	ports, err := svc.FindFreeOnInterface(context.Background(), domain.TCP, lo, &domain.PortRange{Start: port, End: port}, 1)
+	if !errors.Is(err, domain.ErrNoFreePort) {
+		t.Fatalf("expected port %d to be busy on %s, got %v, %v", port, lo, ports, err)
+	}
+
+	_, err = svc.FindFreeOnInterface(context.Background(), domain.TCP, "no-such-iface0", nil, 1)
+	if !errors.Is(err, domain.ErrUnknownInterface) {
+		t.Errorf("expected ErrUnknownInterface, got %v", err)
+	}
+}
+
+func loopbackInterface() (string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", err
+	}
+	for _, ifi := range ifaces {
+		if ifi.Flags&net.FlagLoopback != 0 {
+			return ifi.Name, nil
+		}
+	}
+	return "", errors.New("no loopback interface")
+}
+
+func TestFreeSCTPPortOrUnsupported(t *testing.T) {
+	svc := services.NewFindFreePortService()
+	svc.SetSCTPProber(platform.NewSCTPProber())
+	ports, err := svc.FindFree(context.Background(), domain.SCTP, &domain.PortRange{Start: 10000, End: 20000}, 2)
+	if errors.Is(err, domain.ErrUnsupported) {
+		t.Skipf("SCTP unavailable: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) != 2 || ports[0] == ports[1] {
+		t.Fatalf("expected 2 distinct ports, got %v", ports)
+	}
+}